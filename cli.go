@@ -0,0 +1,270 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//command is a node of the command tree. Commands either run or have subcommands
+type command struct {
+	name    string
+	aliases []string
+	//args describes the arguments in the usage, e.g. "<mod name...>"
+	args    string
+	summary string
+	//minArgs and maxArgs limit the amount of arguments. maxArgs is -1 if there is no limit
+	minArgs int
+	maxArgs int
+	//flags registers the flags of the command besides the global ones
+	flags func(fs *flag.FlagSet)
+	//complete returns the candidates for the arguments of the command
+	complete    func() []string
+	run         func(args []string)
+	subcommands []*command
+	hidden      bool
+	//rawArgs passes all arguments to run without parsing flags
+	rawArgs bool
+}
+
+//find returns the subcommand with the name or alias
+func (c *command) find(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+		for _, alias := range sub.aliases {
+			if alias == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+//flagSet returns the flags accepted by the command including the global ones
+func (c *command) flagSet(path []*command) *flag.FlagSet {
+	fs := flag.NewFlagSet(commandName(path), flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	if c.flags != nil {
+		c.flags(fs)
+	}
+	fs.Usage = func() {
+		printCommandHelp(path)
+	}
+	return fs
+}
+
+//execute selects the subcommand from the arguments and runs it.
+//Flags may appear anywhere and are passed on to the command that is run
+func execute(path []*command, args []string) {
+	c := path[len(path)-1]
+	if c.rawArgs {
+		c.run(args)
+		return
+	}
+
+	fs := c.flagSet(path)
+	if len(c.subcommands) == 0 {
+		positional, err := parseFlags(fs, args)
+		if err == flag.ErrHelp {
+			return
+		}
+		if err != nil {
+			fmt.Printf("Run '%s --help' for usage\n", commandName(path))
+			os.Exit(2)
+		}
+
+		if len(positional) < c.minArgs || (c.maxArgs >= 0 && len(positional) > c.maxArgs) {
+			fmt.Printf("%s: %s\n", commandName(path), argsError(c, len(positional)))
+			fmt.Printf("Usage: %s\n", usage(path))
+			os.Exit(2)
+		}
+
+		c.run(positional)
+		return
+	}
+
+	name, rest := splitCommand(c, fs, args)
+	if name == "" {
+		if _, err := parseFlags(fs, rest); err == flag.ErrHelp {
+			return
+		}
+		printCommandHelp(path)
+		os.Exit(1)
+	}
+
+	sub := c.find(name)
+	if sub == nil {
+		unknownCommand(path, name)
+		os.Exit(1)
+	}
+	execute(append(path, sub), rest)
+}
+
+//parseFlags parses flags between the arguments and returns the remaining arguments.
+//Everything after "--" is an argument
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional, tail []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, tail = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return append(positional, tail...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+//splitCommand returns the first argument which is not a flag and the other arguments
+func splitCommand(c *command, fs *flag.FlagSet, args []string) (string, []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			rest := append(append([]string{}, args[:i]...), args[i+1:]...)
+			return arg, rest
+		}
+
+		if takesValue(c, fs, arg) {
+			i++
+		}
+	}
+	return "", args
+}
+
+//takesValue checks if the flag argument is followed by its value.
+//Flags of subcommands are known as well since they may appear before the subcommand
+func takesValue(c *command, fs *flag.FlagSet, arg string) bool {
+	name := strings.TrimLeft(arg, "-")
+	if strings.Contains(name, "=") {
+		return false
+	}
+
+	f := lookupFlag(c, fs, name)
+	if f == nil {
+		return false
+	}
+
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return false
+	}
+	return true
+}
+
+//lookupFlag finds the flag in the flag set or the flags of the subcommands
+func lookupFlag(c *command, fs *flag.FlagSet, name string) *flag.Flag {
+	if f := fs.Lookup(name); f != nil {
+		return f
+	}
+
+	for _, sub := range c.subcommands {
+		subFlags := flag.NewFlagSet(sub.name, flag.ContinueOnError)
+		if sub.flags != nil {
+			sub.flags(subFlags)
+		}
+		if f := lookupFlag(sub, subFlags, name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func argsError(c *command, got int) string {
+	switch {
+	case c.maxArgs == 0:
+		return "does not take arguments"
+	case c.minArgs == c.maxArgs:
+		return fmt.Sprintf("expects %d argument(s) but got %d", c.minArgs, got)
+	case got < c.minArgs:
+		return fmt.Sprintf("expects at least %d argument(s)", c.minArgs)
+	default:
+		return fmt.Sprintf("expects at most %d argument(s)", c.maxArgs)
+	}
+}
+
+//unknownCommand prints an error with the commands that are spelled similarly
+func unknownCommand(path []*command, name string) {
+	fmt.Printf("%s: '%s' is not a command\n", commandName(path), name)
+
+	if suggestions := suggest(path[len(path)-1], name); len(suggestions) > 0 {
+		fmt.Printf("Did you mean %s?\n", strings.Join(suggestions, " or "))
+	}
+	fmt.Printf("Run '%s --help' for a list of commands\n", commandName(path))
+}
+
+//suggest returns the subcommands whose name or alias is close to the name
+func suggest(c *command, name string) []string {
+	var res []string
+	for _, sub := range c.subcommands {
+		if sub.hidden {
+			continue
+		}
+
+		for _, candidate := range append([]string{sub.name}, sub.aliases...) {
+			if strings.HasPrefix(candidate, name) || distance(candidate, name) <= 2 {
+				res = append(res, "'"+sub.name+"'")
+				break
+			}
+		}
+	}
+	return res
+}
+
+//distance is the Levenshtein distance between two strings
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	res := values[0]
+	for _, value := range values[1:] {
+		if value < res {
+			res = value
+		}
+	}
+	return res
+}
+
+//commandName returns the full name of the command, e.g. "ccmu profile create"
+func commandName(path []*command) string {
+	var names []string
+	for _, c := range path {
+		names = append(names, c.name)
+	}
+	return strings.Join(names, " ")
+}
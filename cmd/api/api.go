@@ -1,12 +1,32 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/api"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
 )
 
+//shutdownTimeout is how long running operations may take after a shutdown was requested
+const shutdownTimeout = 2 * time.Minute
+
+//Token reads the api token of the current user from the configuration or the environment.
+//It is sent by clients to change mods
+func Token() (string, error) {
+	cfg, err := config.Effective("")
+	if err != nil {
+		return "", err
+	}
+	return cfg.APIToken, nil
+}
+
 //Start api server
 func Start() {
 	StartAt("localhost", 9392)
@@ -15,14 +35,109 @@ func Start() {
 //StartAt host and port
 func StartAt(host string, port int) {
 	url := fmt.Sprintf("%s:%d", host, port)
+	listener, err := net.Listen("tcp", url)
+	if err != nil {
+		fmt.Printf("Could not start API server because of an error in %s\n", err.Error())
+		os.Exit(1)
+	}
+
 	fmt.Printf("API server listening on %s\n", url)
+	serve(listener)
+}
+
+//StartUnix listens on a unix domain socket at the given path
+func StartUnix(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("Could not remove old socket because of an error in %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		fmt.Printf("Could not start API server because of an error in %s\n", err.Error())
+		os.Exit(1)
+	}
+	defer os.Remove(path)
+
+	if err := os.Chmod(path, 0600); err != nil {
+		fmt.Printf("Could not restrict access to the socket because of an error in %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("API server listening on %s\n", path)
+	serve(listener)
+}
+
+func serve(listener net.Listener) {
+	security, err := api.LoadSecurity()
+	if err != nil {
+		fmt.Printf("Could not load the API token because of an error in %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if path, err := config.Path(); err == nil {
+		fmt.Printf("Clients have to send the token stored in %s to change mods\n", path)
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range api.Routes() {
+		if route.Protected {
+			mux.HandleFunc(route.Path, security.Protect(route.Handler))
+		} else {
+			mux.HandleFunc(route.Path, security.Allow(route.Handler))
+		}
+	}
+
+	//There is no write timeout since installations and event streams may take very long
+	server := &http.Server{
+		Handler:           api.LogRequests(os.Stderr, api.FreshMods(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		waitForShutdown(server)
+	}()
+
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		fmt.Printf("API server stopped because of an error in %s\n", err.Error())
+		os.Exit(1)
+	}
+	<-stopped
+}
+
+//waitForShutdown stops the server on SIGINT or SIGTERM after running operations are done.
+//A second signal stops it immediately
+func waitForShutdown(server *http.Server) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	fmt.Println("Shutting down API server, waiting for running operations")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 
-	http.HandleFunc("/api/v1/install", api.Install)
-	http.HandleFunc("/api/v1/uninstall", api.Uninstall)
-	http.HandleFunc("/api/v1/update", api.Update)
-	http.HandleFunc("/api/v1/get/local", api.GetLocalMods)
-	http.HandleFunc("/api/v1/get/global", api.GetGlobalMods)
-	http.HandleFunc("/api/v1/get/outdated", api.Outdated)
+	jobsStopped := make(chan error, 1)
+	go func() {
+		jobsStopped <- api.StopJobs(ctx)
+	}()
 
-	http.ListenAndServe(url, nil)
+	if err := server.Shutdown(ctx); err != nil {
+		fmt.Printf("Could not shut down API server gracefully because of an error in %s\n", err.Error())
+		server.Close()
+	}
+	if err := <-jobsStopped; err != nil {
+		fmt.Printf("Stopped waiting for running operations because of an error in %s\n", err.Error())
+	}
 }
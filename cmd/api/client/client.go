@@ -0,0 +1,266 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//Client calls the api server of the tool
+type Client struct {
+	//BaseURL of the server, e.g. http://localhost:9392
+	BaseURL string
+	//Token is sent with requests that change mods
+	Token string
+	//HTTPClient is used for all requests. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+//Error is returned if the server reports a failure
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%s (%d %s)", err.Message, err.Status, err.Code)
+}
+
+//New creates a client for the server at the base url. Clients on the same machine can use the token returned by api.Token
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+	}
+}
+
+//NewUnix creates a client for a server listening on a unix domain socket
+func NewUnix(path, token string) *Client {
+	return &Client{
+		BaseURL: "http://unix",
+		Token:   token,
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+//Health checks if the server is running
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	var res HealthResponse
+	return &res, c.do(ctx, "GET", "/healthz", nil, &res)
+}
+
+//Version returns the version of the server
+func (c *Client) Version(ctx context.Context) (*VersionResponse, error) {
+	var res VersionResponse
+	return &res, c.do(ctx, "GET", "/api/v1/version", nil, &res)
+}
+
+//Install mods and their dependencies
+func (c *Client) Install(ctx context.Context, req InstallRequest) (*InstallResponse, error) {
+	var res InstallResponse
+	return &res, c.do(ctx, "POST", "/api/v1/install", req, &res)
+}
+
+//Uninstall mods
+func (c *Client) Uninstall(ctx context.Context, req UninstallRequest) (*UninstallResponse, error) {
+	var res UninstallResponse
+	return &res, c.do(ctx, "POST", "/api/v1/uninstall", req, &res)
+}
+
+//Update mods or all outdated mods if no names are given
+func (c *Client) Update(ctx context.Context, req UpdateRequest) (*UpdateResponse, error) {
+	var res UpdateResponse
+	return &res, c.do(ctx, "POST", "/api/v1/update", req, &res)
+}
+
+//Enable disabled mods
+func (c *Client) Enable(ctx context.Context, req EnableRequest) (*EnableResponse, error) {
+	var res EnableResponse
+	return &res, c.do(ctx, "POST", "/api/v1/enable", req, &res)
+}
+
+//Disable mods without removing them
+func (c *Client) Disable(ctx context.Context, req EnableRequest) (*EnableResponse, error) {
+	var res EnableResponse
+	return &res, c.do(ctx, "POST", "/api/v1/disable", req, &res)
+}
+
+//LocalMods lists the installed mods
+func (c *Client) LocalMods(ctx context.Context, req LocalModsRequest) (*LocalModsResponse, error) {
+	var res LocalModsResponse
+	return &res, c.do(ctx, "POST", "/api/v1/get/local", req, &res)
+}
+
+//GlobalMods lists the available mods
+func (c *Client) GlobalMods(ctx context.Context, req GlobalModsRequest) (*GlobalModsResponse, error) {
+	var res GlobalModsResponse
+	return &res, c.do(ctx, "POST", "/api/v1/get/global", req, &res)
+}
+
+//Outdated lists the installed mods which have a newer version
+func (c *Client) Outdated(ctx context.Context, req OutdatedRequest) (*OutdatedResponse, error) {
+	var res OutdatedResponse
+	return &res, c.do(ctx, "POST", "/api/v1/get/outdated", req, &res)
+}
+
+//HistoryQuery selects entries of the journal of a game. Empty fields are ignored
+type HistoryQuery struct {
+	//Game is the id of a registered game
+	Game string
+	//Since is a duration like "24h", a date or a RFC 3339 timestamp
+	Since string
+	Mod   string
+	Limit int
+}
+
+//History lists the operations which changed the mods of a game
+func (c *Client) History(ctx context.Context, query HistoryQuery) (*HistoryResponse, error) {
+	values := url.Values{}
+	for key, value := range map[string]string{"game": query.Game, "since": query.Since, "mod": query.Mod} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	path := "/api/v1/history"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	var res HistoryResponse
+	return &res, c.do(ctx, "GET", path, nil, &res)
+}
+
+//SubmitJob runs an operation in the background
+func (c *Client) SubmitJob(ctx context.Context, req JobRequest) (*JobResponse, error) {
+	var res JobResponse
+	return &res, c.do(ctx, "POST", "/api/v1/jobs", req, &res)
+}
+
+//Jobs lists all jobs known to the server
+func (c *Client) Jobs(ctx context.Context) (*JobsResponse, error) {
+	var res JobsResponse
+	return &res, c.do(ctx, "GET", "/api/v1/jobs", nil, &res)
+}
+
+//Job returns the state of a job
+func (c *Client) Job(ctx context.Context, id string) (*JobResponse, error) {
+	var res JobResponse
+	return &res, c.do(ctx, "GET", "/api/v1/jobs/"+url.PathEscape(id), nil, &res)
+}
+
+//CancelJob stops a queued or running job
+func (c *Client) CancelJob(ctx context.Context, id string) (*JobResponse, error) {
+	var res JobResponse
+	return &res, c.do(ctx, "DELETE", "/api/v1/jobs/"+url.PathEscape(id), nil, &res)
+}
+
+//JobEvents calls handle for every event of the job until it is finished and returns its final status
+func (c *Client) JobEvents(ctx context.Context, id string, handle func(Event)) (string, error) {
+	resp, err := c.send(ctx, "GET", "/api/v1/jobs/"+url.PathEscape(id)+"/events", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var eventType string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := []byte(strings.TrimPrefix(line, "data: "))
+			if eventType == "status" {
+				var status string
+				err := json.Unmarshal(data, &status)
+				return status, err
+			}
+
+			var event Event
+			if err := json.Unmarshal(data, &event); err != nil {
+				return "", err
+			}
+			handle(event)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.ErrUnexpectedEOF
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, res interface{}) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+//send performs the request and turns error responses into an *Error
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+
+		var res ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			return nil, &Error{Status: resp.StatusCode, Message: resp.Status}
+		}
+		return nil, &Error{Status: resp.StatusCode, Code: string(res.Code), Message: res.Message}
+	}
+	return resp, nil
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/api"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Requests and responses of the api server
+type (
+	InstallRequest      = api.InstallRequest
+	InstallResponse     = api.InstallResponse
+	UninstallRequest    = api.UninstallRequest
+	UninstallResponse   = api.UninstallResponse
+	UpdateRequest       = api.UpdateRequest
+	UpdateResponse      = api.UpdateResponse
+	EnableRequest       = api.EnableRequest
+	EnableResponse      = api.EnableResponse
+	LocalModsRequest    = api.LocalModsRequest
+	LocalModsResponse   = api.LocalModsResponse
+	GlobalModsRequest   = api.GlobalModsRequest
+	GlobalModsResponse  = api.GlobalModsResponse
+	OutdatedRequest     = api.OutdatedRequest
+	OutdatedResponse    = api.OutdatedResponse
+	OutdatedDescription = api.OutdatedDescription
+	JobRequest          = api.JobRequest
+	JobResponse         = api.JobResponse
+	JobsResponse        = api.JobsResponse
+	Job                 = api.Job
+	HistoryResponse     = api.HistoryResponse
+	HealthResponse      = api.HealthResponse
+	VersionResponse     = api.VersionResponse
+	ErrorResponse       = api.ErrorResponse
+)
+
+//Types used inside of the responses
+type (
+	LocalMod  = local.Mod
+	GlobalMod = global.Mod
+	Stats     = cmd.Stats
+	Event     = cmd.Event
+	LogEntry  = cmd.LogEntry
+	LogChange = cmd.LogChange
+)
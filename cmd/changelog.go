@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+)
+
+//Release contains the release notes of a version of a mod
+type Release = global.Release
+
+//changes returns the release notes of the versions of the mod since from, newest first
+func changes(ctx context.Context, name, from string) ([]Release, error) {
+	mod, err := global.GetMod(name)
+	if err != nil {
+		return nil, err
+	}
+	return mod.Changes(ctx, from)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Sources of a setting
+const (
+	SourceDefault = "default"
+	SourceUser    = "user"
+	SourceGame    = "game"
+	SourceEnv     = "env"
+)
+
+//Setting is a configuration value and where it comes from
+type Setting struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Env         string `json:"env"`
+	PerGame     bool   `json:"perGame"`
+	Value       string `json:"value"`
+	Source      string `json:"source"`
+}
+
+//Settings lists the settings which apply to the current game
+func Settings() ([]*Setting, error) {
+	user, err := config.Load()
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not read configuration because an error occured in %s", err.Error())
+	}
+
+	override := &config.Config{}
+	if game, err := local.GetGame(); err == nil {
+		override, err = config.LoadGame(game)
+		if err != nil {
+			return nil, errcode.Wrap(err, "cmd: Could not read configuration of the game because an error occured in %s", err.Error())
+		}
+	}
+
+	effective, err := local.Settings()
+	if err != nil {
+		return nil, errcode.New(errcode.InvalidRequest, "cmd: Could not read configuration because an error occured in %s", err.Error())
+	}
+
+	settings := []*Setting{}
+	for _, key := range config.Keys {
+		setting := &Setting{
+			Name:        key.Name,
+			Description: key.Description,
+			Env:         key.Env,
+			PerGame:     key.PerGame,
+			Value:       key.Get(effective),
+			Source:      SourceDefault,
+		}
+
+		if _, ok := os.LookupEnv(key.Env); ok {
+			setting.Source = SourceEnv
+		} else if key.PerGame && key.IsSet(override) {
+			setting.Source = SourceGame
+		} else if key.IsSet(user) {
+			setting.Source = SourceUser
+		}
+
+		if setting.Value == "" {
+			setting.Value = key.Default
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+//GetSetting returns the setting with the name which applies to the current game
+func GetSetting(name string) (*Setting, error) {
+	if config.FindKey(name) == nil {
+		return nil, errcode.New(errcode.NotFound, "cmd: Unknown setting '%s'", name)
+	}
+
+	settings, err := Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, setting := range settings {
+		if setting.Name == name {
+			return setting, nil
+		}
+	}
+	return nil, errcode.New(errcode.NotFound, "cmd: Unknown setting '%s'", name)
+}
+
+//SetSetting changes a setting in the configuration of the user or, if game is true,
+//in the configuration file of the current game. An empty value resets the setting
+func SetSetting(name, value string, game bool) error {
+	key := config.FindKey(name)
+	if key == nil {
+		return errcode.New(errcode.NotFound, "cmd: Unknown setting '%s'", name)
+	}
+
+	//Settings like modDirs change which mods are found
+	defer local.Invalidate()
+
+	if !game {
+		cfg, err := config.Load()
+		if err != nil {
+			return errcode.Wrap(err, "cmd: Could not read configuration because an error occured in %s", err.Error())
+		}
+		if err := key.Set(cfg, value); err != nil {
+			return errcode.New(errcode.InvalidRequest, "cmd: Invalid value for '%s': %s", name, err.Error())
+		}
+		if err := cfg.Save(); err != nil {
+			return errcode.Wrap(err, "cmd: Could not save configuration because an error occured in %s", err.Error())
+		}
+		return nil
+	}
+
+	if !key.PerGame {
+		return errcode.New(errcode.InvalidRequest, "cmd: Setting '%s' can not be changed for a single game", name)
+	}
+
+	dir, err := local.GetGame()
+	if err != nil {
+		return errcode.New(errcode.GameNotFound, "cmd: Could not find game folder")
+	}
+
+	cfg, err := config.LoadGame(dir)
+	if err != nil {
+		return errcode.Wrap(err, "cmd: Could not read configuration of the game because an error occured in %s", err.Error())
+	}
+	if err := key.Set(cfg, value); err != nil {
+		return errcode.New(errcode.InvalidRequest, "cmd: Invalid value for '%s': %s", name, err.Error())
+	}
+	if err := cfg.SaveGame(dir); err != nil {
+		return errcode.Wrap(err, "cmd: Could not save configuration of the game because an error occured in %s", err.Error())
+	}
+	return nil
+}
+
+//SettingNames returns the names of all settings
+func SettingNames() []string {
+	names := []string{}
+	for _, key := range config.Keys {
+		names = append(names, key.Name)
+	}
+	return names
+}
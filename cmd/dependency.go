@@ -3,55 +3,65 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
 	"github.com/Masterminds/semver"
 )
 
-func installDependencies(mod local.Mod, stats *Stats) error {
+func planDependencies(mod local.Mod, stats *Stats) ([]*change, error) {
+	var changes []*change
 	for name, version := range mod.Dependencies {
-		if err := installDependency(name, version, stats); err != nil {
-			return err
+		ch, err := planDependency(name, version, stats)
+		if err != nil {
+			return changes, err
+		}
+		if ch != nil {
+			changes = append(changes, ch)
 		}
 	}
-	return nil
+	return changes, nil
 }
 
-func installDependency(name, version string, stats *Stats) error {
+func planDependency(name, version string, stats *Stats) (*change, error) {
 	ver, err := semver.NewConstraint(version)
 	if err != nil {
-		stats.AddWarning(fmt.Sprintf("cmd: Mod '%s' had an invalid version number '%s'", name, version))
-		return nil
+		stats.addCodedWarning(errcode.InvalidMod, fmt.Sprintf("cmd: Mod '%s' had an invalid version number '%s'", name, version))
+		return nil, nil
 	}
 
 	newest, err := global.GetMod(name)
 	if err != nil {
-		return installTool(name, stats)
+		return nil, installTool(name, stats)
 	}
 
 	newestVer, err := semver.NewVersion(newest.Version)
 	if err != nil {
 		stats.AddWarning(fmt.Sprintf("cmd: Could not parse mod list: Mod '%s' has an invalid version number '%s'", name, version))
-		return nil
+		return nil, nil
 	}
 
 	if !ver.Check(newestVer) {
-		stats.AddWarning(fmt.Sprintf("cmd: Could not update mod '%s' to %s because the newest version is %s", name, version, newest.Version))
-		return nil
+		stats.addCodedWarning(errcode.DependencyConflict, fmt.Sprintf("cmd: Could not update mod '%s' to %s because the newest version is %s", name, version, newest.Version))
+		return nil, nil
 	}
 
-	mod, err := local.GetMod(name)
+	mod, err := local.GetMod(stats.game, name)
 	if err != nil {
-		return installMod(name, stats)
+		return planInstall(name, stats)
+	}
+
+	if !mod.Enabled {
+		stats.AddWarning(fmt.Sprintf("cmd: Mod '%s' is required but disabled", name))
 	}
 
 	outdated, err := mod.Outdated()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if outdated {
-		return updateMod(name, stats)
+		return planUpdate(name, stats)
 	}
-	return nil
+	return nil, nil
 }
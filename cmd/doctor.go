@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/tools"
+	"github.com/Masterminds/semver"
+)
+
+//Kinds of problems found by Doctor
+const (
+	ProblemMalformedManifest     = "malformed-manifest"
+	ProblemDuplicateMod          = "duplicate-mod"
+	ProblemMissingDependency     = "missing-dependency"
+	ProblemUnsatisfiedDependency = "unsatisfied-dependency"
+	ProblemDisabledDependency    = "disabled-dependency"
+	ProblemInvalidVersion        = "invalid-version"
+	ProblemLeftoverFiles         = "leftover-files"
+	ProblemFolderName            = "folder-name"
+	ProblemMissingCCLoader       = "missing-ccloader"
+)
+
+//Problem is an issue of the mods of a game
+type Problem struct {
+	Kind       string `json:"kind"`
+	Mod        string `json:"mod,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+	//Fixable is true if Doctor can fix the problem itself
+	Fixable bool `json:"fixable"`
+	Fixed   bool `json:"fixed"`
+	//FixError is set if fixing the problem failed
+	FixError string `json:"fixError,omitempty"`
+
+	fix func(stats *Stats) error
+}
+
+//Doctor searches the game for problems with its mods. Fixable problems are fixed if fix is set.
+//Fixes are recorded like other operations so they can be rolled back. Dry runs only report the problems
+func Doctor(fix bool, opts Options) ([]*Problem, *Stats, error) {
+	game, err := opts.game()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	problems, err := diagnose(game)
+	if err != nil {
+		return nil, nil, errcode.Wrap(err, "cmd: Could not check mods because an error occured in %s", err.Error())
+	}
+
+	if !fix || opts.DryRun || !fixable(problems) {
+		return problems, nil, nil
+	}
+
+	stats := newStats(opts, game)
+	if err := stats.begin("doctor", nil); err != nil {
+		_, err = stats.finish(err)
+		return problems, stats, err
+	}
+
+	for _, problem := range fixOrder(problems) {
+		if err := stats.context().Err(); err != nil {
+			_, err = stats.finish(err)
+			return problems, stats, err
+		}
+
+		err := problem.fix(stats)
+		local.Invalidate()
+		if err != nil {
+			problem.FixError = err.Error()
+			stats.AddWarning(fmt.Sprintf("cmd: Could not fix %s because an error occured in %s", problem.Kind, err.Error()))
+			continue
+		}
+		problem.Fixed = true
+	}
+
+	_, err = stats.finish(nil)
+	return problems, stats, err
+}
+
+//fixOrder returns the fixable problems in the order they are fixed in.
+//Dependencies are enabled before missing ones are installed since installing checks them
+func fixOrder(problems []*Problem) []*Problem {
+	var ordered []*Problem
+	for _, kind := range []string{ProblemLeftoverFiles, ProblemDuplicateMod, ProblemFolderName, ProblemDisabledDependency, ProblemMissingDependency, ProblemUnsatisfiedDependency} {
+		for _, problem := range problems {
+			if problem.Kind == kind && problem.fix != nil {
+				ordered = append(ordered, problem)
+			}
+		}
+	}
+	return ordered
+}
+
+func fixable(problems []*Problem) bool {
+	for _, problem := range problems {
+		if problem.fix != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func diagnose(game string) ([]*Problem, error) {
+	mods, broken, err := local.ScanMods(game)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []*Problem{}
+	add := func(problem *Problem) {
+		problem.Fixable = problem.fix != nil
+		problems = append(problems, problem)
+	}
+
+	if !local.HasCCLoader(game) {
+		add(&Problem{
+			Kind:       ProblemMissingCCLoader,
+			Path:       game,
+			Message:    "CCLoader is not installed so no mods are loaded",
+			Suggestion: "Install CCLoader from https://github.com/CCDirectLink/CCLoader",
+		})
+	}
+
+	for _, dir := range leftoverDirs(game) {
+		dir := dir
+		add(&Problem{
+			Kind:       ProblemLeftoverFiles,
+			Path:       dir,
+			Message:    fmt.Sprintf("Temporary files of an interrupted operation were left in '%s'", dir),
+			Suggestion: "Remove the folder if no other operation is running",
+			fix: func(stats *Stats) error {
+				//Another operation may have started using the folder since it was checked
+				if inUse(dir) {
+					return nil
+				}
+				return os.RemoveAll(dir)
+			},
+		})
+	}
+
+	for _, mod := range broken {
+		problem := &Problem{
+			Kind:       ProblemMalformedManifest,
+			Path:       mod.Path,
+			Message:    fmt.Sprintf("The manifest in '%s' could not be read: %s", mod.Path, mod.Err.Error()),
+			Suggestion: "Reinstall the mod or remove the folder",
+		}
+		if os.IsNotExist(mod.Err) {
+			problem.Message = fmt.Sprintf("'%s' does not contain a ccmod.json or package.json", mod.Path)
+			problem.Suggestion = "Remove the folder if it does not belong to a mod"
+		}
+		add(problem)
+	}
+
+	duplicates := map[string]bool{}
+	for _, group := range duplicateMods(mods) {
+		keep := group[0]
+		duplicates[keep.Name] = true
+		for _, mod := range group[1:] {
+			mod := mod
+			add(&Problem{
+				Kind:       ProblemDuplicateMod,
+				Mod:        mod.Name,
+				Path:       mod.BasePath,
+				Message:    fmt.Sprintf("'%s' %s is also installed in '%s'", mod.Name, mod.Version, keep.BasePath),
+				Suggestion: fmt.Sprintf("Remove '%s' and keep version %s", mod.BasePath, keep.Version),
+				fix: func(stats *Stats) error {
+					if err := os.RemoveAll(mod.BasePath); err != nil {
+						return err
+					}
+					stats.Removed++
+					return nil
+				},
+			})
+		}
+	}
+
+	//Dependencies are checked against the mods which are kept if there are duplicates
+	installed := map[string]local.Mod{}
+	for _, mod := range mods {
+		installed[mod.Name] = mod
+	}
+	for _, group := range duplicateMods(mods) {
+		installed[group[0].Name] = group[0]
+	}
+
+	for _, mod := range mods {
+		if _, err := semver.NewVersion(mod.Version); err != nil {
+			add(&Problem{
+				Kind:       ProblemInvalidVersion,
+				Mod:        mod.Name,
+				Path:       mod.BasePath,
+				Message:    fmt.Sprintf("'%s' has the invalid version '%s'", mod.Name, mod.Version),
+				Suggestion: "Reinstall the mod or ask its author to use semantic versioning",
+			})
+		}
+
+		if folderName(mod) != mod.Name && !duplicates[mod.Name] {
+			add(folderNameProblem(mod))
+		}
+	}
+
+	for _, mod := range mods {
+		if !mod.Enabled {
+			continue
+		}
+		for _, name := range sortedKeys(mod.Dependencies) {
+			if problem := checkDependency(mod, name, mod.Dependencies[name], installed); problem != nil {
+				add(problem)
+			}
+		}
+	}
+	return problems, nil
+}
+
+//leftoverAge is how long the temporary files of an operation have to be unchanged before they
+//are considered to be left over. Running operations keep writing into their folders
+const leftoverAge = time.Hour
+
+//leftoverDirs returns the folders containing temporary files of earlier operations.
+//Folders which were changed recently belong to running operations and are skipped
+func leftoverDirs(game string) []string {
+	candidates := []string{filepath.Join(game, "installing")}
+	if cfg, err := config.Effective(game); err == nil {
+		if cache, err := cfg.CacheDir(); err == nil {
+			candidates = append(candidates, filepath.Join(cache, "installing"))
+			downloads, _ := filepath.Glob(filepath.Join(cache, "downloads*"))
+			candidates = append(candidates, downloads...)
+		}
+	}
+
+	var dirs []string
+	for _, dir := range candidates {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() && !inUse(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+//inUse checks if the folder or anything in it was changed within leftoverAge
+func inUse(dir string) bool {
+	limit := time.Now().Add(-leftoverAge)
+	used := false
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			//Files removed while walking belong to a running operation as well
+			used = true
+			return filepath.SkipDir
+		}
+		if info.ModTime().After(limit) {
+			used = true
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return used
+}
+
+//duplicateMods groups mods installed more than once. The mod which is kept comes first:
+//enabled mods before disabled ones and newer versions before older ones
+func duplicateMods(mods []local.Mod) [][]local.Mod {
+	byName := map[string][]local.Mod{}
+	var names []string
+	for _, mod := range mods {
+		if _, ok := byName[mod.Name]; !ok {
+			names = append(names, mod.Name)
+		}
+		byName[mod.Name] = append(byName[mod.Name], mod)
+	}
+
+	var groups [][]local.Mod
+	for _, name := range names {
+		group := byName[name]
+		if len(group) < 2 {
+			continue
+		}
+
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].Enabled != group[j].Enabled {
+				return group[i].Enabled
+			}
+			return newer(group[i].Version, group[j].Version)
+		})
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func newer(a, b string) bool {
+	va, err := semver.NewVersion(a)
+	if err != nil {
+		return false
+	}
+	vb, err := semver.NewVersion(b)
+	if err != nil {
+		return true
+	}
+	return va.GreaterThan(vb)
+}
+
+//folderName returns the name of the folder of the mod or of its file without the extension if it is packed
+func folderName(mod local.Mod) string {
+	name := filepath.Base(mod.BasePath)
+	if mod.Packaging == local.PackagingCCMod {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name
+}
+
+func folderNameProblem(mod local.Mod) *Problem {
+	file := mod.Name
+	if mod.Packaging == local.PackagingCCMod {
+		file += filepath.Ext(mod.BasePath)
+	}
+
+	problem := &Problem{
+		Kind:       ProblemFolderName,
+		Mod:        mod.Name,
+		Path:       mod.BasePath,
+		Message:    fmt.Sprintf("'%s' is installed in '%s'", mod.Name, filepath.Base(mod.BasePath)),
+		Suggestion: fmt.Sprintf("Rename '%s' to '%s'", filepath.Base(mod.BasePath), file),
+	}
+
+	target := filepath.Join(filepath.Dir(mod.BasePath), file)
+	if mod.Name == "" || filepath.Base(mod.Name) != mod.Name {
+		problem.Suggestion = "Ask the author of the mod to use a name which is a valid folder name"
+		return problem
+	}
+	if _, err := os.Stat(target); err == nil {
+		problem.Suggestion = fmt.Sprintf("Rename '%s' to '%s' after removing the existing '%s'", filepath.Base(mod.BasePath), file, file)
+		return problem
+	}
+
+	problem.fix = func(stats *Stats) error {
+		return os.Rename(mod.BasePath, target)
+	}
+	return problem
+}
+
+//checkDependency returns the problem with the dependency of the enabled mod or nil if it is fine
+func checkDependency(mod local.Mod, name, version string, installed map[string]local.Mod) *Problem {
+	if tools.Find(name) != nil {
+		//Tools are not installed as mods
+		return nil
+	}
+
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return &Problem{
+			Kind:       ProblemInvalidVersion,
+			Mod:        mod.Name,
+			Path:       mod.BasePath,
+			Message:    fmt.Sprintf("'%s' requires '%s' with the invalid version range '%s'", mod.Name, name, version),
+			Suggestion: "Ask the author of the mod to fix the version range",
+		}
+	}
+
+	dep, ok := installed[name]
+	if !ok {
+		problem := &Problem{
+			Kind:       ProblemMissingDependency,
+			Mod:        mod.Name,
+			Path:       mod.BasePath,
+			Message:    fmt.Sprintf("'%s' requires '%s' %s which is not installed", mod.Name, name, version),
+			Suggestion: fmt.Sprintf("Install '%s'", name),
+		}
+		if available(name, constraint) {
+			problem.fix = func(stats *Stats) error {
+				if _, err := local.GetMod(stats.game, name); err == nil {
+					//Another fix already installed it
+					return nil
+				}
+				ch, err := planInstall(name, stats)
+				if err != nil || ch == nil {
+					return err
+				}
+				return execute([]*change{ch}, stats)
+			}
+		} else {
+			problem.Suggestion = fmt.Sprintf("Install a version of '%s' matching %s by hand", name, version)
+		}
+		return problem
+	}
+
+	current, err := semver.NewVersion(dep.Version)
+	if err == nil && !constraint.Check(current) {
+		problem := &Problem{
+			Kind:       ProblemUnsatisfiedDependency,
+			Mod:        mod.Name,
+			Path:       mod.BasePath,
+			Message:    fmt.Sprintf("'%s' requires '%s' %s but %s is installed", mod.Name, name, version, dep.Version),
+			Suggestion: fmt.Sprintf("Update '%s'", name),
+		}
+		if available(name, constraint) {
+			problem.fix = func(stats *Stats) error {
+				if dep, err := local.GetMod(stats.game, name); err == nil {
+					if current, err := semver.NewVersion(dep.Version); err == nil && constraint.Check(current) {
+						//Another fix already updated it
+						return nil
+					}
+				}
+				ch, err := planUpdate(name, stats)
+				if err != nil || ch == nil {
+					return err
+				}
+				return execute([]*change{ch}, stats)
+			}
+		} else {
+			problem.Suggestion = fmt.Sprintf("No version of '%s' matching %s is available. Update '%s' or install a matching version by hand", name, version, mod.Name)
+		}
+		return problem
+	}
+
+	if !dep.Enabled {
+		return &Problem{
+			Kind:       ProblemDisabledDependency,
+			Mod:        mod.Name,
+			Path:       mod.BasePath,
+			Message:    fmt.Sprintf("'%s' requires '%s' which is disabled", mod.Name, name),
+			Suggestion: fmt.Sprintf("Enable '%s'", name),
+			fix: func(stats *Stats) error {
+				return toggle(name, true, stats)
+			},
+		}
+	}
+	return nil
+}
+
+//available checks if the newest version of the mod in the mod database matches the constraint
+func available(name string, constraint *semver.Constraints) bool {
+	mod, err := global.GetMod(name)
+	if err != nil {
+		return false
+	}
+
+	version, err := semver.NewVersion(mod.Version)
+	return err == nil && constraint.Check(version)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
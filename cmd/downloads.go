@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/install"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Downloads keeps the archives of downloaded mods so operations sharing it download every mod only once,
+//e.g. when the same mods are installed into several games. It has to be closed to remove the archives
+type Downloads struct {
+	mutex    sync.Mutex
+	dir      string
+	archives map[string]string
+}
+
+//NewDownloads creates an empty store of downloads in the cache directory
+func NewDownloads() (*Downloads, error) {
+	cfg, err := local.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := cfg.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cache, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir(cache, "downloads")
+	if err != nil {
+		return nil, err
+	}
+	return &Downloads{dir: dir, archives: map[string]string{}}, nil
+}
+
+//Close removes the kept archives
+func (d *Downloads) Close() error {
+	return os.RemoveAll(d.dir)
+}
+
+//inspect reads the manifest of the mod from its kept archive or downloads and keeps the archive first.
+//The size of the archive is returned as well
+func (d *Downloads) inspect(ctx context.Context, game, name string, progress install.Progress) (local.Mod, int64, error) {
+	mod, err := global.GetMod(name)
+	if err != nil {
+		return local.Mod{}, 0, err
+	}
+
+	d.mutex.Lock()
+	path, ok := d.archives[mod.ArchiveLink]
+	d.mutex.Unlock()
+	if ok {
+		return install.InspectArchive(path, name)
+	}
+
+	downloaded, err := install.DownloadArchive(ctx, game, mod, progress)
+	if err != nil {
+		return local.Mod{}, 0, err
+	}
+
+	d.mutex.Lock()
+	path = filepath.Join(d.dir, strconv.Itoa(len(d.archives)))
+	err = os.Rename(downloaded, path)
+	if err == nil {
+		d.archives[mod.ArchiveLink] = path
+	}
+	d.mutex.Unlock()
+
+	//Failing to keep the archive only means that it is downloaded again
+	if err != nil {
+		defer os.Remove(downloaded)
+		return install.InspectArchive(downloaded, name)
+	}
+	return install.InspectArchive(path, name)
+}
+
+//get opens the kept archive of the mod or downloads and keeps it
+func (d *Downloads) get(ctx context.Context, game, name string, progress install.Progress) (*install.Package, error) {
+	mod, err := global.GetMod(name)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	path, ok := d.archives[mod.ArchiveLink]
+	d.mutex.Unlock()
+
+	if ok {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return install.Open(ctx, game, mod, file, progress)
+	}
+
+	pkg, err := install.DownloadMod(ctx, game, mod, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	path = filepath.Join(d.dir, strconv.Itoa(len(d.archives)))
+	file, err := os.Create(path)
+	if err != nil {
+		return pkg, nil
+	}
+	defer file.Close()
+
+	//Failing to keep the archive only means that it is downloaded again
+	if err := pkg.WriteArchive(file); err == nil {
+		d.archives[mod.ArchiveLink] = path
+	}
+	return pkg, nil
+}
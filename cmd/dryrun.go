@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/install"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/journal"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Actions of a planned change
+const (
+	ActionInstall = "install"
+	ActionUpdate  = "update"
+	ActionRemove  = "remove"
+	ActionEnable  = "enable"
+	ActionDisable = "disable"
+)
+
+//PlannedChange is a change an operation would make if it was not a dry run
+type PlannedChange struct {
+	Mod    string `json:"mod"`
+	Action string `json:"action"`
+	//From is the installed version. It is empty if the mod is not installed
+	From string `json:"from,omitempty"`
+	//To is the version after the change. It is empty if the mod is removed or unknown
+	To string `json:"to,omitempty"`
+	//Size is the size of the download in bytes if it is known
+	Size int64 `json:"size,omitempty"`
+	//Changes are the release notes of the versions an update installs if Options.Changelog is set
+	Changes []Release `json:"changes,omitempty"`
+}
+
+func (stats *Stats) dryRun() bool {
+	return stats.options.DryRun
+}
+
+//plan records a change of a dry run
+func (stats *Stats) plan(change PlannedChange) {
+	stats.Plan = append(stats.Plan, change)
+}
+
+//planned checks if the dry run already plans to change the mod
+func (stats *Stats) planned(name string) bool {
+	for _, change := range stats.Plan {
+		if change.Mod == name {
+			return true
+		}
+	}
+	return false
+}
+
+//simulate resolves the changes and their dependencies like execute without changing the game.
+//The archives are downloaded into the cache to read the dependencies and kept in Options.Downloads if it is set
+func simulate(changes []*change, stats *Stats) error {
+	for len(changes) > 0 {
+		var pending []*change
+		for _, ch := range unique(changes) {
+			if !stats.planned(ch.name) {
+				pending = append(pending, ch)
+			}
+		}
+
+		parallel(pending, stats, func(ch *change) {
+			if stats.options.Downloads != nil {
+				ch.manifest, ch.size, ch.err = stats.options.Downloads.inspect(stats.context(), stats.game, ch.name, stats.progress(ch.name))
+			} else {
+				ch.manifest, ch.size, ch.err = install.Inspect(stats.context(), stats.game, ch.name, stats.progress(ch.name))
+			}
+			if ch.err != nil {
+				ch.err = errcode.Wrap(ch.err, "cmd: Could not %s '%s' because an error occured in %s", ch.verb(), ch.name, ch.err.Error())
+			}
+		})
+
+		changes = nil
+		for _, ch := range pending {
+			if ch.err != nil {
+				return ch.err
+			}
+
+			stats.plan(plannedChange(ch, stats))
+
+			deps, err := planDependencies(ch.manifest, stats)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, deps...)
+		}
+	}
+	return nil
+}
+
+//plannedChange describes the change of a dry run
+func plannedChange(ch *change, stats *Stats) PlannedChange {
+	res := PlannedChange{
+		Mod:    ch.name,
+		Action: ActionInstall,
+		To:     ch.manifest.Version,
+		Size:   ch.size,
+	}
+
+	if ch.update {
+		res.Action = ActionUpdate
+		if mod, err := local.GetMod(stats.game, ch.name); err == nil {
+			res.From = mod.Version
+		}
+
+		if stats.options.Changelog && res.From != "" {
+			//Missing release notes are no reason to stop the update
+			changes, err := changes(stats.context(), ch.name, res.From)
+			if err != nil {
+				stats.AddWarning(fmt.Sprintf("cmd: Could not read changelog of '%s' because an error occured in %s", ch.name, err.Error()))
+			}
+			res.Changes = changes
+		}
+	}
+	return res
+}
+
+//planDiff records the changes between the current and the wanted mods of a game
+func planDiff(current, wanted []local.Mod, stats *Stats) {
+	actions := map[string]string{
+		journal.ActionInstalled: ActionInstall,
+		journal.ActionUpdated:   ActionUpdate,
+		journal.ActionRemoved:   ActionRemove,
+		journal.ActionEnabled:   ActionEnable,
+		journal.ActionDisabled:  ActionDisable,
+	}
+
+	for _, change := range journal.Diff(current, wanted) {
+		stats.plan(PlannedChange{
+			Mod:    change.Mod,
+			Action: actions[change.Action],
+			From:   change.From,
+			To:     change.To,
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Enable mods so CCLoader loads them again
+func Enable(args []string) (*Stats, error) {
+	return EnableWith(args, Options{})
+}
+
+//EnableWith enables mods using the given options
+func EnableWith(args []string, opts Options) (*Stats, error) {
+	return setEnabled(args, true, opts)
+}
+
+//Disable mods without uninstalling them
+func Disable(args []string) (*Stats, error) {
+	return DisableWith(args, Options{})
+}
+
+//DisableWith disables mods using the given options
+func DisableWith(args []string, opts Options) (*Stats, error) {
+	return setEnabled(args, false, opts)
+}
+
+func setEnabled(args []string, enabled bool, opts Options) (*Stats, error) {
+	if len(args) == 0 {
+		return nil, errcode.New(errcode.InvalidRequest, "cmd: No mods changed since no mods were specified")
+	}
+
+	game, err := opts.game()
+	if err != nil {
+		return nil, err
+	}
+
+	operation := "disable"
+	if enabled {
+		operation = "enable"
+	}
+	stats := newStats(opts, game)
+	if err := stats.begin(operation, args); err != nil {
+		return stats.finish(err)
+	}
+	for _, name := range args {
+		if err := stats.context().Err(); err != nil {
+			return stats.finish(err)
+		}
+
+		if err := toggle(name, enabled, stats); err != nil {
+			return stats.finish(err)
+		}
+	}
+
+	return stats.finish(nil)
+}
+
+func toggle(name string, enabled bool, stats *Stats) error {
+	mod, err := local.GetMod(stats.game, name)
+	if err != nil && stats.dryRun() && stats.planned(name) {
+		//The mod would have been installed by the same operation
+		return nil
+	}
+	if err != nil {
+		stats.addCodedWarning(errcode.NotInstalled, fmt.Sprintf("cmd: Could not find mod '%s'", name))
+		return nil
+	}
+
+	if mod.Enabled == enabled {
+		return nil
+	}
+
+	if stats.dryRun() {
+		action := ActionDisable
+		if enabled {
+			action = ActionEnable
+		}
+		stats.plan(PlannedChange{Mod: name, Action: action, From: mod.Version, To: mod.Version})
+		return nil
+	}
+
+	if err := mod.SetEnabled(stats.game, enabled); err != nil {
+		return errcode.Wrap(err, "cmd: Could not change mod '%s' because an error occured in %s", name, err.Error())
+	}
+
+	if enabled {
+		stats.Enabled++
+	} else {
+		stats.Disabled++
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Game is a registered installation of the game which can be selected by its id
+type Game = config.Game
+
+//Games lists the registered games and the id or path of the default game
+func Games() ([]Game, string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", errcode.Wrap(err, "cmd: Could not read configuration because an error occured in %s", err.Error())
+	}
+
+	games := cfg.Games
+	if games == nil {
+		games = []Game{}
+	}
+	return games, cfg.DefaultGame, nil
+}
+
+//AddGame registers the game in the folder. An id is derived from the folder if none is given
+func AddGame(game Game) (*Game, error) {
+	path, err := filepath.Abs(game.Path)
+	if err != nil || !local.IsGame(path) {
+		return nil, errcode.New(errcode.GameNotFound, "cmd: '%s' does not contain the game", game.Path)
+	}
+	game.Path = path
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not read configuration because an error occured in %s", err.Error())
+	}
+
+	if game.ID == "" {
+		game.ID = uniqueGameID(cfg, strings.ToLower(filepath.Base(path)))
+	} else if cfg.FindGame(game.ID) != nil {
+		return nil, errcode.New(errcode.AlreadyExists, "cmd: A game with the id '%s' already exists", game.ID)
+	}
+
+	cfg.Games = append(cfg.Games, game)
+	if err := cfg.Save(); err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not save configuration because an error occured in %s", err.Error())
+	}
+	return &game, nil
+}
+
+func uniqueGameID(cfg *config.Config, base string) string {
+	id := base
+	for i := 2; cfg.FindGame(id) != nil; i++ {
+		id = base + strconv.Itoa(i)
+	}
+	return id
+}
+
+//RemoveGame forgets a registered game. The game files are not touched
+func RemoveGame(id string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errcode.Wrap(err, "cmd: Could not read configuration because an error occured in %s", err.Error())
+	}
+
+	if cfg.FindGame(id) == nil {
+		return errcode.New(errcode.GameNotFound, "cmd: Could not find game '%s'", id)
+	}
+
+	var games []Game
+	for _, game := range cfg.Games {
+		if game.ID != id {
+			games = append(games, game)
+		}
+	}
+	cfg.Games = games
+	if cfg.DefaultGame == id {
+		cfg.DefaultGame = ""
+	}
+
+	if err := cfg.Save(); err != nil {
+		return errcode.Wrap(err, "cmd: Could not save configuration because an error occured in %s", err.Error())
+	}
+	return nil
+}
+
+//SetDefaultGame selects the registered game used if no game is given
+func SetDefaultGame(id string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errcode.Wrap(err, "cmd: Could not read configuration because an error occured in %s", err.Error())
+	}
+
+	if cfg.FindGame(id) == nil {
+		return errcode.New(errcode.GameNotFound, "cmd: Could not find game '%s'", id)
+	}
+
+	cfg.DefaultGame = id
+	if err := cfg.Save(); err != nil {
+		return errcode.Wrap(err, "cmd: Could not save configuration because an error occured in %s", err.Error())
+	}
+	return nil
+}
+
+//ScanGames discovers installations in the default locations of Steam, GOG and itch
+//and registers the ones which are not registered yet. The new games are returned
+func ScanGames() ([]Game, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not read configuration because an error occured in %s", err.Error())
+	}
+
+	added := []Game{}
+	for _, found := range local.Discover() {
+		if cfg.FindGamePath(found.Path) != nil {
+			continue
+		}
+
+		game := Game{
+			ID:     uniqueGameID(cfg, found.Source),
+			Path:   found.Path,
+			Source: found.Source,
+		}
+		cfg.Games = append(cfg.Games, game)
+		added = append(added, game)
+	}
+
+	if len(added) == 0 {
+		return added, nil
+	}
+	if err := cfg.Save(); err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not save configuration because an error occured in %s", err.Error())
+	}
+	return added, nil
+}
+
+//GameIDs returns the ids of all registered games
+func GameIDs() []string {
+	games, _, _ := Games()
+	ids := []string{}
+	for _, game := range games {
+		ids = append(ids, game.ID)
+	}
+	return ids
+}
+
+//GamePath returns the absolute folder of the game selected by a path or the id of a registered game.
+//Selections which do not contain the game are only made absolute so they can still be compared
+func GamePath(selection string) string {
+	if cfg, err := config.Effective(""); err == nil {
+		selection = cfg.ResolveGame(selection)
+	}
+
+	path, err := filepath.Abs(selection)
+	if err != nil {
+		return filepath.Clean(selection)
+	}
+	if dir, err := local.FindGame(path); err == nil {
+		return dir
+	}
+	return path
+}
@@ -3,71 +3,86 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
-	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/install"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/tools"
 )
 
-var installed = 0
-
 //Install a mod
 func Install(args []string) (*Stats, error) {
+	return InstallWith(args, Options{})
+}
+
+//InstallWith installs mods using the given options
+func InstallWith(args []string, opts Options) (*Stats, error) {
 	if len(args) == 0 {
-		return nil, fmt.Errorf("cmd: No mods installed since no mods were specified")
+		return nil, errcode.New(errcode.InvalidRequest, "cmd: No mods installed since no mods were specified")
 	}
 
-	if _, err := local.GetGame(); err != nil {
-		return nil, fmt.Errorf("cmd: Could not find game folder")
+	game, err := opts.game()
+	if err != nil {
+		return nil, err
 	}
 
 	if _, err := global.FetchModData(); err != nil {
-		return nil, fmt.Errorf("cmd: Could not download mod data because an error occured in %s", err.Error())
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd: Could not download mod data because an error occured in %s", err.Error())
 	}
 
-	stats := &Stats{}
+	stats := newStats(opts, game)
+	if err := stats.begin("install", args); err != nil {
+		return stats.finish(err)
+	}
 
+	var changes []*change
 	for _, name := range args {
-		if _, err := local.GetMod(name); err == nil {
-			stats.AddWarning(fmt.Sprintf("cmd: Could not install '%s' because it was already installed", name))
+		if _, err := local.GetMod(stats.game, name); err == nil {
+			stats.addCodedWarning(errcode.AlreadyInstalled, fmt.Sprintf("cmd: Could not install '%s' because it was already installed", name))
 			continue
 		}
 
-		if err := installMod(name, stats); err != nil {
-			return stats, err
+		ch, err := planInstall(name, stats)
+		if err != nil {
+			return stats.finish(err)
+		}
+		if ch != nil {
+			changes = append(changes, ch)
 		}
 	}
 
-	return stats, nil
+	return stats.finish(execute(changes, stats))
 }
 
-func installMod(name string, stats *Stats) error {
-	if _, err := global.GetMod(name); err != nil {
-		return installTool(name, stats)
+//planInstall returns the change installing the mod. Tools are installed right away
+func planInstall(name string, stats *Stats) (*change, error) {
+	if err := stats.context().Err(); err != nil {
+		return nil, err
 	}
 
-	if err := install.Install(name, false); err != nil {
-		return fmt.Errorf("cmd: Could not install '%s' because an error occured in %s", name, err.Error())
-	}
+	stats.report(Event{Type: EventResolving, Mod: name})
 
-	mod, err := local.GetMod(name)
-	if err != nil {
-		stats.AddWarning(fmt.Sprintf("cmd: Installed '%s' but it seems to be an invalid mod", name))
-		return nil
+	if _, err := global.GetMod(name); err != nil {
+		return nil, installTool(name, stats)
 	}
 
-	stats.Installed++
-	return installDependencies(mod, stats)
+	return &change{name: name}, nil
 }
 
 func installTool(name string, stats *Stats) error {
 	tool := tools.Find(name)
 	if tool == nil {
-		stats.AddWarning(fmt.Sprintf("cmd: Could find mod or tool '%s'", name))
+		stats.addCodedWarning(errcode.ModNotFound, fmt.Sprintf("cmd: Could find mod or tool '%s'", name))
+		return nil
+	}
+
+	if stats.dryRun() {
+		stats.plan(PlannedChange{Mod: name, Action: ActionInstall})
 		return nil
 	}
 
 	err := tool.Install()
+	//Tools like CCLoader bring the configuration of the mod folders
+	local.Invalidate()
 	if err != nil {
 		return err
 	}
@@ -1,10 +1,79 @@
 package api
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//ErrorResponse is returned if a request fails before it could be processed
+type ErrorResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Code    errcode.Code `json:"code"`
+}
+
+//selectedGame returns the path or id of the game selected by a request.
+//It is empty if the request does not select one so the game of the server is used
+func selectedGame(game *string) string {
+	if game == nil {
+		return ""
+	}
+	return *game
+}
+
+//findGame returns the folder of the game selected by a request or the game of the server
+func findGame(game *string) (string, error) {
+	dir, err := local.FindGame(selectedGame(game))
+	if err != nil {
+		return "", errcode.New(errcode.GameNotFound, "cmd/internal/api: Could not find game folder")
+	}
+	return dir, nil
+}
 
 func setHeaders(w http.ResponseWriter) {
-	w.Header().Add("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+	w.Header().Set("Content-Type", "application/json")
+}
+
+//writeError sends the error with the status matching its code
+func writeError(w http.ResponseWriter, err error) {
+	setHeaders(w)
+	w.WriteHeader(httpStatus(err))
+	json.NewEncoder(w).Encode(&ErrorResponse{
+		Success: false,
+		Message: err.Error(),
+		Code:    errcode.Of(err),
+	})
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, errcode.New(errcode.MethodNotAllowed, "cmd/internal/api: Method %s is not allowed", r.Method))
+}
+
+//httpStatus maps the code of the error to a HTTP status
+func httpStatus(err error) int {
+	switch errcode.Of(err) {
+	case errcode.InvalidRequest:
+		return http.StatusBadRequest
+	case errcode.MethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case errcode.Unauthorized:
+		return http.StatusUnauthorized
+	case errcode.Forbidden:
+		return http.StatusForbidden
+	case errcode.NotFound, errcode.GameNotFound, errcode.ModNotFound, errcode.NotInstalled:
+		return http.StatusNotFound
+	case errcode.AlreadyInstalled, errcode.AlreadyExists, errcode.DependencyConflict, errcode.Canceled:
+		return http.StatusConflict
+	case errcode.InvalidMod:
+		return http.StatusUnprocessableEntity
+	case errcode.DownloadFailed, errcode.HashMismatch:
+		return http.StatusBadGateway
+	case errcode.DatabaseUnavailable, errcode.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
 }
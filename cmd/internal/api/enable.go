@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+)
+
+//EnableRequest for incoming requests to enable or disable mods
+type EnableRequest struct {
+	Game  *string  `json:"game"`
+	Names []string `json:"names"`
+	//DryRun only returns the planned changes in the stats
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+//EnableResponse for requests to enable or disable mods
+type EnableResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Code    errcode.Code `json:"code,omitempty"`
+	Stats   *cmd.Stats   `json:"stats,omitempty"`
+}
+
+//Enable mods via api request
+func Enable(w http.ResponseWriter, r *http.Request) {
+	setEnabled(w, r, "enable")
+}
+
+//Disable mods via api request
+func Disable(w http.ResponseWriter, r *http.Request) {
+	setEnabled(w, r, "disable")
+}
+
+func setEnabled(w http.ResponseWriter, r *http.Request, operation string) {
+	if r.Method != "POST" {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	setHeaders(w)
+
+	decoder := json.NewDecoder(r.Body)
+	stats, err := enable(r.Context(), decoder, operation)
+
+	encoder := json.NewEncoder(w)
+	if err == nil {
+		encoder.Encode(&EnableResponse{
+			Success: true,
+			Stats:   stats,
+		})
+	} else {
+		w.WriteHeader(httpStatus(err))
+		encoder.Encode(&EnableResponse{
+			Success: false,
+			Message: err.Error(),
+			Code:    errcode.Of(err),
+			Stats:   stats,
+		})
+	}
+}
+
+func enable(ctx context.Context, decoder *json.Decoder, operation string) (*cmd.Stats, error) {
+	var req EnableRequest
+	if err := decoder.Decode(&req); err != nil {
+		return nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error())
+	}
+
+	return runJob(ctx, JobRequest{Operation: operation, Game: req.Game, Names: req.Names, DryRun: req.DryRun})
+}
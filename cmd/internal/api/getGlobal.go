@@ -2,10 +2,9 @@ package api
 
 import (
 	"encoding/json"
-	"flag"
-	"fmt"
 	"net/http"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
 )
 
@@ -18,6 +17,7 @@ type GlobalModsRequest struct {
 type GlobalModsResponse struct {
 	Success bool                  `json:"success"`
 	Message string                `json:"message,omitempty"`
+	Code    errcode.Code          `json:"code,omitempty"`
 	Mods    map[string]global.Mod `json:"mods"`
 }
 
@@ -39,9 +39,11 @@ func GetGlobalMods(w http.ResponseWriter, r *http.Request) {
 			Mods:    mods,
 		})
 	} else {
+		w.WriteHeader(httpStatus(err))
 		encoder.Encode(&GlobalModsResponse{
 			Success: false,
 			Message: err.Error(),
+			Code:    errcode.Of(err),
 		})
 	}
 }
@@ -50,16 +52,12 @@ func getGlobalMods(decoder *json.Decoder) (map[string]global.Mod, error) {
 	if decoder != nil {
 		var req GlobalModsRequest
 		if err := decoder.Decode(&req); err != nil {
-			return nil, fmt.Errorf("cmd/internal/api: Could not parse request body: %s", err.Error())
-		}
-
-		if req.Game != nil {
-			if err := flag.Set("game", *req.Game); err != nil {
-				return nil, fmt.Errorf("cmd/internal/api: Could set game flag: %s", err.Error())
-			}
+			return nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error())
 		}
 	}
 
+	//The mod database is the same for every game so the selected game is ignored
+
 	res, err := global.FetchModData()
 	if err != nil {
 		return nil, err
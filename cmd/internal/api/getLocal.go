@@ -2,10 +2,9 @@ package api
 
 import (
 	"encoding/json"
-	"flag"
-	"fmt"
 	"net/http"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
 )
 
@@ -16,9 +15,10 @@ type LocalModsRequest struct {
 
 //LocalModsResponse contains a list of installed mods
 type LocalModsResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Mods    []local.Mod `json:"mods"`
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Code    errcode.Code `json:"code,omitempty"`
+	Mods    []local.Mod  `json:"mods"`
 }
 
 //GetLocalMods returns all installed mods
@@ -39,26 +39,26 @@ func GetLocalMods(w http.ResponseWriter, r *http.Request) {
 			Mods:    mods,
 		})
 	} else {
+		w.WriteHeader(httpStatus(err))
 		encoder.Encode(&LocalModsResponse{
 			Success: false,
 			Message: err.Error(),
+			Code:    errcode.Of(err),
 		})
 	}
 }
 
 func getLocalMods(decoder *json.Decoder) ([]local.Mod, error) {
+	var req LocalModsRequest
 	if decoder != nil {
-		var req LocalModsRequest
 		if err := decoder.Decode(&req); err != nil {
-			return nil, fmt.Errorf("cmd/internal/api: Could not parse request body: %s", err.Error())
-		}
-
-		if req.Game != nil {
-			if err := flag.Set("game", *req.Game); err != nil {
-				return nil, fmt.Errorf("cmd/internal/api: Could set game flag: %s", err.Error())
-			}
+			return nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error())
 		}
 	}
 
-	return local.GetMods()
+	game, err := findGame(req.Game)
+	if err != nil {
+		return nil, err
+	}
+	return local.GetMods(game)
 }
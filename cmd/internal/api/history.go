@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+)
+
+//HistoryResponse contains the operations which changed the mods of a game
+type HistoryResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message,omitempty"`
+	Code    errcode.Code    `json:"code,omitempty"`
+	Entries []*cmd.LogEntry `json:"entries"`
+}
+
+//History returns the journal of a game
+func History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	setHeaders(w)
+
+	entries, err := history(r)
+
+	encoder := json.NewEncoder(w)
+	if err == nil {
+		encoder.Encode(&HistoryResponse{
+			Success: true,
+			Entries: entries,
+		})
+	} else {
+		w.WriteHeader(httpStatus(err))
+		encoder.Encode(&HistoryResponse{
+			Success: false,
+			Message: err.Error(),
+			Code:    errcode.Of(err),
+		})
+	}
+}
+
+func history(r *http.Request) ([]*cmd.LogEntry, error) {
+	game, err := requestGame(r)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.URL.Query()
+	since, err := cmd.ParseSince(query.Get("since"))
+	if err != nil {
+		return nil, err
+	}
+
+	filter := cmd.LogFilter{
+		Since: since,
+		Mod:   query.Get("mod"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 0 {
+			return nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Invalid limit '%s'", limit)
+		}
+	}
+
+	return cmd.Log(game, filter)
+}
@@ -1,38 +1,41 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"flag"
-	"fmt"
 	"net/http"
 
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 )
 
 //InstallRequest for incoming installation requests
 type InstallRequest struct {
 	Game  *string  `json:"game"`
 	Names []string `json:"names"`
+	//DryRun only returns the planned changes in the stats
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 //InstallResponse for installation requests
 type InstallResponse struct {
-	Success bool       `json:"success"`
-	Message string     `json:"message,omitempty"`
-	Stats   *cmd.Stats `json:"stats,omitempty"`
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Code    errcode.Code `json:"code,omitempty"`
+	Stats   *cmd.Stats   `json:"stats,omitempty"`
 }
 
 //Install a mod via api request
 func Install(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method Not Allowed", 405)
+		methodNotAllowed(w, r)
 		return
 	}
 
 	setHeaders(w)
 
 	decoder := json.NewDecoder(r.Body)
-	stats, err := install(decoder)
+	stats, err := install(r.Context(), decoder)
 
 	encoder := json.NewEncoder(w)
 	if err == nil {
@@ -41,25 +44,21 @@ func Install(w http.ResponseWriter, r *http.Request) {
 			Stats:   stats,
 		})
 	} else {
+		w.WriteHeader(httpStatus(err))
 		encoder.Encode(&InstallResponse{
 			Success: false,
 			Message: err.Error(),
+			Code:    errcode.Of(err),
 			Stats:   stats,
 		})
 	}
 }
 
-func install(decoder *json.Decoder) (*cmd.Stats, error) {
+func install(ctx context.Context, decoder *json.Decoder) (*cmd.Stats, error) {
 	var req InstallRequest
 	if err := decoder.Decode(&req); err != nil {
-		return nil, fmt.Errorf("cmd/internal/api: Could not parse request body: %s", err.Error())
+		return nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error())
 	}
 
-	if req.Game != nil {
-		if err := flag.Set("game", *req.Game); err != nil {
-			return nil, fmt.Errorf("cmd/internal/api: Could set game flag: %s", err.Error())
-		}
-	}
-
-	return cmd.Install(req.Names)
+	return runJob(ctx, JobRequest{Operation: "install", Game: req.Game, Names: req.Names, DryRun: req.DryRun})
 }
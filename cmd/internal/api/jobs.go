@@ -0,0 +1,450 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+)
+
+//States of a job
+const (
+	JobQueued   = "queued"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+//JobRequest for incoming job submissions
+type JobRequest struct {
+	Operation string   `json:"operation"`
+	Game      *string  `json:"game"`
+	Names     []string `json:"names"`
+	//DryRun only returns the planned changes in the stats of the job
+	DryRun bool `json:"dryRun,omitempty"`
+	//Changelog adds the release notes of the new versions to the planned changes of a dry run update
+	Changelog bool `json:"changelog,omitempty"`
+}
+
+//JobResponse contains the state of a single job
+type JobResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Job     *Job   `json:"job,omitempty"`
+}
+
+//JobsResponse contains the state of all known jobs
+type JobsResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Jobs    []*Job `json:"jobs"`
+}
+
+//Job is an install, update, uninstall, enable or disable operation running in the background
+type Job struct {
+	ID        string       `json:"id"`
+	Operation string       `json:"operation"`
+	Names     []string     `json:"names"`
+	DryRun    bool         `json:"dryRun,omitempty"`
+	Changelog bool         `json:"changelog,omitempty"`
+	Status    string       `json:"status"`
+	Message   string       `json:"message,omitempty"`
+	Code      errcode.Code `json:"code,omitempty"`
+	Stats     *cmd.Stats   `json:"stats,omitempty"`
+	Events    []cmd.Event  `json:"events"`
+
+	game    *string
+	cancel  context.CancelFunc
+	ctx     context.Context
+	changed chan struct{}
+	done    chan struct{}
+}
+
+var (
+	jobsMutex   sync.Mutex
+	jobs        = map[string]*Job{}
+	jobOrder    []string
+	jobQueue    = make(chan *Job, 64)
+	jobsStart   sync.Once
+	jobsStopped = make(chan struct{})
+)
+
+//maxFinishedJobs is the amount of finished jobs that are kept for status queries
+const maxFinishedJobs = 100
+
+//Jobs lists all jobs or submits a new one
+func Jobs(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w)
+	encoder := json.NewEncoder(w)
+
+	switch r.Method {
+	case "GET":
+		encoder.Encode(&JobsResponse{
+			Success: true,
+			Jobs:    listJobs(),
+		})
+	case "POST":
+		job, err := submitJob(json.NewDecoder(r.Body))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		encoder.Encode(&JobResponse{
+			Success: true,
+			Job:     job.snapshot(),
+		})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+//JobByID returns the state of a job, cancels it or streams its events
+func JobByID(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/")
+	stream := false
+	if strings.HasSuffix(id, "/events") {
+		id = strings.TrimSuffix(id, "/events")
+		stream = true
+	}
+
+	job := findJob(id)
+	if job == nil {
+		writeError(w, errcode.New(errcode.NotFound, "cmd/internal/api: Could not find job '%s'", id))
+		return
+	}
+
+	switch {
+	case stream && r.Method == "GET":
+		streamJob(w, r, job)
+	case r.Method == "GET":
+		setHeaders(w)
+		json.NewEncoder(w).Encode(&JobResponse{
+			Success: true,
+			Job:     job.snapshot(),
+		})
+	case r.Method == "DELETE":
+		job.cancel()
+		setHeaders(w)
+		json.NewEncoder(w).Encode(&JobResponse{
+			Success: true,
+			Job:     job.snapshot(),
+		})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func submitJob(decoder *json.Decoder) (*Job, error) {
+	var req JobRequest
+	if err := decoder.Decode(&req); err != nil {
+		return nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error())
+	}
+	return startJob(req)
+}
+
+//startJob queues the operation of the request
+func startJob(req JobRequest) (*Job, error) {
+	switch req.Operation {
+	case "install", "update", "uninstall", "enable", "disable":
+	default:
+		return nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Unknown operation '%s'", req.Operation)
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, fmt.Errorf("cmd/internal/api: Could not create job id: %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		ID:        id,
+		Operation: req.Operation,
+		Names:     req.Names,
+		DryRun:    req.DryRun,
+		Changelog: req.Changelog,
+		Status:    JobQueued,
+		Events:    []cmd.Event{},
+		game:      req.Game,
+		ctx:       ctx,
+		cancel:    cancel,
+		changed:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	jobsMutex.Lock()
+	select {
+	case <-jobsStopped:
+		jobsMutex.Unlock()
+		cancel()
+		return nil, errcode.New(errcode.Unavailable, "cmd/internal/api: The server is shutting down")
+	default:
+	}
+	jobs[id] = job
+	jobOrder = append(jobOrder, id)
+	pruneJobs()
+	jobsMutex.Unlock()
+
+	jobsStart.Do(func() {
+		go runJobs()
+	})
+
+	select {
+	case jobQueue <- job:
+	default:
+		job.finish(nil, fmt.Errorf("cmd/internal/api: Too many queued jobs"))
+	}
+	return job, nil
+}
+
+//runJob queues the operation of the request and waits until it is finished
+func runJob(ctx context.Context, req JobRequest) (*cmd.Stats, error) {
+	job, err := startJob(req)
+	if err != nil {
+		return nil, err
+	}
+	return waitJob(ctx, job)
+}
+
+//waitJob waits until the job is finished and returns its stats and error. The job keeps running if the context is done first
+func waitJob(ctx context.Context, job *Job) (*cmd.Stats, error) {
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	res := job.snapshot()
+	if res.Status != JobDone {
+		return res.Stats, errcode.New(res.Code, "%s", res.Message)
+	}
+	return res.Stats, nil
+}
+
+//runJobs executes the queued jobs one after another so operations do not change the same game at once
+func runJobs() {
+	for job := range jobQueue {
+		job.run()
+	}
+}
+
+func (job *Job) run() {
+	if job.ctx.Err() != nil {
+		job.finish(nil, job.ctx.Err())
+		return
+	}
+
+	job.update(func() {
+		job.Status = JobRunning
+	})
+
+	opts := cmd.Options{
+		Context:   job.ctx,
+		Progress:  job.addEvent,
+		DryRun:    job.DryRun,
+		Changelog: job.Changelog,
+		Game:      selectedGame(job.game),
+	}
+
+	var stats *cmd.Stats
+	var err error
+	switch job.Operation {
+	case "install":
+		stats, err = cmd.InstallWith(job.Names, opts)
+	case "update":
+		stats, err = cmd.UpdateWith(job.Names, opts)
+	case "uninstall":
+		stats, err = cmd.UninstallWith(job.Names, opts)
+	case "enable":
+		stats, err = cmd.EnableWith(job.Names, opts)
+	case "disable":
+		stats, err = cmd.DisableWith(job.Names, opts)
+	}
+	job.finish(stats, err)
+}
+
+func (job *Job) addEvent(event cmd.Event) {
+	job.update(func() {
+		job.Events = append(job.Events, event)
+	})
+}
+
+func (job *Job) finish(stats *cmd.Stats, err error) {
+	job.update(func() {
+		if job.finished() {
+			return
+		}
+		defer close(job.done)
+
+		job.Stats = stats
+		switch {
+		case err == nil:
+			job.Status = JobDone
+		case job.ctx.Err() != nil:
+			job.Status = JobCanceled
+			job.Message = err.Error()
+			job.Code = errcode.Canceled
+		default:
+			job.Status = JobFailed
+			job.Message = err.Error()
+			job.Code = errcode.Of(err)
+		}
+	})
+	job.cancel()
+}
+
+//update changes the job and wakes up all event streams
+func (job *Job) update(change func()) {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	change()
+	close(job.changed)
+	job.changed = make(chan struct{})
+}
+
+func (job *Job) finished() bool {
+	return job.Status == JobDone || job.Status == JobFailed || job.Status == JobCanceled
+}
+
+//snapshot copies the job so it can be encoded without holding the lock
+func (job *Job) snapshot() *Job {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	res := *job
+	res.Events = append([]cmd.Event{}, job.Events...)
+	return &res
+}
+
+func streamJob(w http.ResponseWriter, r *http.Request, job *Job) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming Not Supported", 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	sent := 0
+	for {
+		jobsMutex.Lock()
+		events := job.Events[sent:]
+		status := job.Status
+		finished := job.finished()
+		changed := job.changed
+		jobsMutex.Unlock()
+
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		sent += len(events)
+
+		if finished {
+			fmt.Fprintf(w, "event: status\ndata: %q\n\n", status)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		case <-jobsStopped:
+			return
+		}
+	}
+}
+
+//StopJobs rejects new jobs, cancels queued ones and waits until all jobs are finished
+//or the context is done
+func StopJobs(ctx context.Context) error {
+	jobsMutex.Lock()
+	select {
+	case <-jobsStopped:
+	default:
+		close(jobsStopped)
+	}
+
+	var pending []*Job
+	for _, job := range jobs {
+		if job.finished() {
+			continue
+		}
+		if job.Status == JobQueued {
+			job.cancel()
+		}
+		pending = append(pending, job)
+	}
+	jobsMutex.Unlock()
+
+	for _, job := range pending {
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func findJob(id string) *Job {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+	return jobs[id]
+}
+
+func listJobs() []*Job {
+	jobsMutex.Lock()
+	ids := append([]string{}, jobOrder...)
+	jobsMutex.Unlock()
+
+	res := []*Job{}
+	for _, id := range ids {
+		if job := findJob(id); job != nil {
+			res = append(res, job.snapshot())
+		}
+	}
+	return res
+}
+
+//pruneJobs forgets the oldest finished jobs. The caller has to hold jobsMutex
+func pruneJobs() {
+	finished := 0
+	for _, id := range jobOrder {
+		if jobs[id].finished() {
+			finished++
+		}
+	}
+
+	var kept []string
+	for _, id := range jobOrder {
+		if finished > maxFinishedJobs && jobs[id].finished() {
+			delete(jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	jobOrder = kept
+}
+
+func newJobID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+var (
+	pathParameter = regexp.MustCompile(`\{(\w+)\}`)
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+//OpenAPI returns the OpenAPI 3 document of the api.
+//It is generated from Routes so it always matches the registered handlers
+func OpenAPI(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w)
+	json.NewEncoder(w).Encode(openAPIDocument())
+}
+
+type object = map[string]interface{}
+
+func openAPIDocument() object {
+	schemas := &schemaBuilder{schemas: object{}}
+	errorResponse := object{
+		"description": "The request failed",
+		"content":     jsonContent(schemas.schema(reflect.TypeOf(ErrorResponse{}))),
+	}
+
+	paths := object{}
+	for _, route := range Routes() {
+		for _, op := range route.Operations {
+			path := op.Path
+			if path == "" {
+				path = route.Path
+			}
+
+			item, ok := paths[path].(object)
+			if !ok {
+				item = object{}
+				paths[path] = item
+			}
+
+			status := op.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			responses := object{
+				strconv.Itoa(status): successResponse(op, status, schemas),
+				"default":            errorResponse,
+			}
+			if op.Async {
+				responses[strconv.Itoa(http.StatusAccepted)] = successResponse(op, http.StatusAccepted, schemas)
+			}
+
+			operation := object{
+				"operationId": op.ID,
+				"summary":     op.Summary,
+				"responses":   responses,
+			}
+
+			var params []object
+			for _, match := range pathParameter.FindAllStringSubmatch(path, -1) {
+				params = append(params, object{
+					"name":     match[1],
+					"in":       "path",
+					"required": true,
+					"schema":   object{"type": "string"},
+				})
+			}
+			for _, name := range op.Query {
+				params = append(params, object{
+					"name":   name,
+					"in":     "query",
+					"schema": object{"type": "string"},
+				})
+			}
+			if params != nil {
+				operation["parameters"] = params
+			}
+
+			if op.Request != nil {
+				operation["requestBody"] = object{
+					"required": true,
+					"content":  jsonContent(schemas.schema(reflect.TypeOf(op.Request))),
+				}
+			}
+
+			if route.Protected && op.Method != "GET" {
+				operation["security"] = []object{{"bearerAuth": []string{}}}
+			}
+
+			item[strings.ToLower(op.Method)] = operation
+		}
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "CrossCode Mod Updater API",
+			"version": cmd.Version,
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": schemas.schemas,
+			"securitySchemes": object{
+				"bearerAuth": object{
+					"type":   "http",
+					"scheme": "bearer",
+				},
+			},
+		},
+	}
+}
+
+func successResponse(op Operation, status int, schemas *schemaBuilder) object {
+	switch {
+	case status == http.StatusNoContent:
+		return object{"description": "Success"}
+	case status == http.StatusAccepted && op.Async:
+		return object{
+			"description": "The operation runs in the background",
+			"content":     jsonContent(schemas.schema(reflect.TypeOf(op.Response))),
+		}
+	case op.Stream:
+		return object{
+			"description": "A stream of server-sent events",
+			"content": object{
+				"text/event-stream": object{"schema": object{"type": "string"}},
+			},
+		}
+	case op.Response == nil:
+		return object{
+			"description": "Success",
+			"content":     jsonContent(object{"type": "object"}),
+		}
+	default:
+		return object{
+			"description": "Success",
+			"content":     jsonContent(schemas.schema(reflect.TypeOf(op.Response))),
+		}
+	}
+}
+
+func jsonContent(schema object) object {
+	return object{
+		"application/json": object{"schema": schema},
+	}
+}
+
+//schemaBuilder converts Go types into JSON schemas following the rules of encoding/json
+type schemaBuilder struct {
+	schemas object
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) object {
+	//Types like time.Time encode themselves as strings
+	if t == reflect.TypeOf(time.Time{}) {
+		return object{"type": "string", "format": "date-time"}
+	}
+	if t.Implements(textMarshaler) || reflect.PtrTo(t).Implements(textMarshaler) {
+		return object{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := b.schema(t.Elem())
+		if _, isRef := schema["$ref"]; isRef {
+			return schema
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+
+		name := schemaName(t)
+		if _, ok := b.schemas[name]; !ok {
+			b.schemas[name] = object{}
+			b.schemas[name] = b.object(t)
+		}
+		return object{"$ref": "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		return object{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}
+	default:
+		return object{}
+	}
+}
+
+func (b *schemaBuilder) object(t reflect.Type) object {
+	properties := object{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, omitempty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		properties[name] = b.schema(field.Type)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	schema := object{
+		"type":       "object",
+		"properties": properties,
+	}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
+
+//schemaName prefixes types from other packages with the package name to avoid collisions
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if strings.HasSuffix(pkg, "/cmd/internal/api") {
+		return t.Name()
+	}
+
+	pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "-", false
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	omitempty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
@@ -0,0 +1,401 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//TestOpenAPIMatchesRoutes checks that every operation of the routes is documented and that the document
+//only contains operations which are served by the handler registered for their path
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	registered := map[string]bool{}
+	routed := map[string]bool{}
+	for _, route := range Routes() {
+		if registered[route.Path] {
+			t.Errorf("%s is registered twice", route.Path)
+		}
+		registered[route.Path] = true
+
+		for _, op := range route.Operations {
+			path := operationPath(route, op)
+			if !servedBy(route.Path, path) {
+				t.Errorf("%s %s is documented for %s which does not serve it", op.Method, path, route.Path)
+			}
+			routed[op.Method+" "+path] = true
+		}
+	}
+
+	documented := map[string]bool{}
+	for path, item := range decodedDocument(t)["paths"].(object) {
+		for method := range item.(object) {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for _, op := range sortedKeys(routed) {
+		if !documented[op] {
+			t.Errorf("%s is routed but not documented", op)
+		}
+	}
+	for _, op := range sortedKeys(documented) {
+		if !routed[op] {
+			t.Errorf("%s is documented but not routed", op)
+		}
+	}
+}
+
+//apiCase is a request to an operation of the api that is expected to succeed
+type apiCase struct {
+	//path replaces the parameters of the documented path and may add a query
+	path string
+	body interface{}
+}
+
+//TestHandlersMatchOpenAPI sends a request to every documented operation and checks the response against its schema
+func TestHandlersMatchOpenAPI(t *testing.T) {
+	game := testGame
+
+	cases := map[string]apiCase{
+		"health":              {},
+		"version":             {},
+		"openapi":             {},
+		"install":             {body: InstallRequest{Names: []string{"b"}}},
+		"uninstall":           {body: UninstallRequest{Names: []string{"b"}}},
+		"update":              {body: UpdateRequest{DryRun: true}},
+		"getLocalMods":        {},
+		"getLocalModsOfGame":  {body: LocalModsRequest{Game: &game}},
+		"getGlobalMods":       {},
+		"getGlobalModsOfGame": {body: GlobalModsRequest{Game: &game}},
+		"getOutdated":         {},
+		"getOutdatedOfGame":   {body: OutdatedRequest{Game: &game, Changelog: true}},
+		"enable":              {body: EnableRequest{Names: []string{"a"}, DryRun: true}},
+		"disable":             {body: EnableRequest{Names: []string{"a"}, DryRun: true}},
+		"getHistory":          {path: "/api/v1/history?limit=10"},
+		"listJobs":            {},
+		"submitJob":           {body: JobRequest{Operation: "install", Names: []string{"b"}, DryRun: true}},
+		"getJob":              {},
+		"streamJobEvents":     {},
+		"cancelJob":           {},
+		"listMods":            {path: "/api/v2/mods?q=a"},
+		"getMod":              {path: "/api/v2/mods/a"},
+		"listInstalled":       {path: "/api/v2/installed?outdated=true"},
+		"getInstalled":        {path: "/api/v2/installed/a"},
+		"putInstalled":        {path: "/api/v2/installed/a?dryRun=true"},
+		"deleteInstalled":     {path: "/api/v2/installed/a?dryRun=true"},
+		"enableInstalled":     {path: "/api/v2/installed/a:enable?dryRun=true"},
+		"disableInstalled":    {path: "/api/v2/installed/a:disable?dryRun=true"},
+		"updateInstalled":     {path: "/api/v2/installed:update?dryRun=true", body: UpdateInstalledRequest{}},
+		"listGames":           {},
+		"registerGame":        {body: map[string]string{"id": "test", "path": game}},
+		"getGame":             {path: "/api/v2/games/test"},
+		"deleteGame":          {path: "/api/v2/games/test"},
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range Routes() {
+		mux.HandleFunc(route.Path, route.Handler)
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	doc := decodedDocument(t)
+	paths := doc["paths"].(object)
+	params := map[string]string{}
+
+	//The operations are requested in the order of the routes so jobs exist before they are read
+	for _, route := range Routes() {
+		for _, op := range route.Operations {
+			c, ok := cases[op.ID]
+			if !ok {
+				t.Errorf("%s has no test case", op.ID)
+				continue
+			}
+
+			documented := operationPath(route, op)
+			path := c.path
+			if path == "" {
+				path = pathParameter.ReplaceAllStringFunc(documented, func(match string) string {
+					return params[strings.Trim(match, "{}")]
+				})
+			}
+			if !matchesTemplate(documented, path) {
+				t.Errorf("%s: %s does not match %s", op.ID, path, documented)
+				continue
+			}
+
+			res, data := send(t, server.URL, op.Method, path, c.body)
+			responses := paths[documented].(object)[strings.ToLower(op.Method)].(object)["responses"].(object)
+			response, ok := responses[strconv.Itoa(res.StatusCode)].(object)
+			if !ok || res.StatusCode >= 400 {
+				t.Errorf("%s: %s %s returned undocumented status %s: %s", op.ID, op.Method, path, res.Status, data)
+				continue
+			}
+
+			content, ok := response["content"].(object)
+			if !ok {
+				if len(data) > 0 {
+					t.Errorf("%s: returned a body although none is documented: %s", op.ID, data)
+				}
+				continue
+			}
+			media, ok := content[mediaType(res)]
+			if !ok {
+				t.Errorf("%s: content type %s is not documented", op.ID, res.Header.Get("Content-Type"))
+				continue
+			}
+			if op.Stream {
+				continue
+			}
+
+			var value interface{}
+			if err := json.Unmarshal(data, &value); err != nil {
+				t.Errorf("%s: invalid JSON: %s", op.ID, err.Error())
+				continue
+			}
+			for _, problem := range validate(doc, media.(object)["schema"].(object), value, "response") {
+				t.Errorf("%s: %s", op.ID, problem)
+			}
+
+			if op.ID == "submitJob" {
+				job, _ := value.(object)["job"].(object)
+				params["id"], _ = job["id"].(string)
+			}
+		}
+	}
+}
+
+func operationPath(route Route, op Operation) string {
+	if op.Path != "" {
+		return op.Path
+	}
+	return route.Path
+}
+
+//servedBy checks if the pattern of a route matches the path like http.ServeMux does
+func servedBy(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(path, pattern)
+	}
+	return path == pattern
+}
+
+func matchesTemplate(template, path string) bool {
+	parts := pathParameter.Split(template, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := regexp.MustCompile("^" + strings.Join(parts, `[^/:]+`) + "$")
+	return expr.MatchString(strings.SplitN(path, "?", 2)[0])
+}
+
+func decodedDocument(t *testing.T) object {
+	//The document is encoded and decoded so it is checked the way clients see it
+	data, err := json.Marshal(openAPIDocument())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc object
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func mediaType(res *http.Response) string {
+	return strings.TrimSpace(strings.SplitN(res.Header.Get("Content-Type"), ";", 2)[0])
+}
+
+func send(t *testing.T, base, method, path string, body interface{}) (*http.Response, []byte) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, base+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, data
+}
+
+//validate returns the differences between the value and the schema. Properties which are not in the schema are
+//reported as well since they mean that the document is out of date
+func validate(doc object, schema object, value interface{}, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		return validate(doc, doc["components"].(object)["schemas"].(object)[name].(object), value, at)
+	}
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || schema["type"] == "array" || schema["type"] == "object" {
+			return nil
+		}
+		if schema["type"] == nil {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s is null but must be a %v", at, schema["type"])}
+	}
+
+	switch schema["type"] {
+	case "object":
+		fields, ok := value.(object)
+		if !ok {
+			return []string{fmt.Sprintf("%s must be an object", at)}
+		}
+
+		var problems []string
+		properties, _ := schema["properties"].(object)
+		extra, _ := schema["additionalProperties"].(object)
+		for name, field := range fields {
+			switch {
+			case properties[name] != nil:
+				problems = append(problems, validate(doc, properties[name].(object), field, at+"."+name)...)
+			case extra != nil:
+				problems = append(problems, validate(doc, extra, field, at+"."+name)...)
+			case properties != nil:
+				problems = append(problems, fmt.Sprintf("%s.%s is not documented", at, name))
+			}
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := fields[name.(string)]; !ok {
+					problems = append(problems, fmt.Sprintf("%s.%s is required", at, name))
+				}
+			}
+		}
+		return problems
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s must be an array", at)}
+		}
+
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, validate(doc, schema["items"].(object), item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return problems
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s must be a string", at)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s must be a boolean", at)}
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			return []string{fmt.Sprintf("%s must be an integer", at)}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{fmt.Sprintf("%s must be a number", at)}
+		}
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//testGame is the folder of the game used by the tests. It has the mod a installed while the mod database
+//contains a newer version of a and the mod b
+var testGame string
+
+//TestMain points the configuration and the cache to temporary folders. The database server is shared by all tests
+//since the mod database is only downloaded once per process
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "ccmu-api")
+	if err != nil {
+		panic(err)
+	}
+
+	testGame = filepath.Join(dir, "game")
+	files := map[string]string{
+		"package.json":               "{}",
+		"assets/node-webkit.html":    "",
+		"assets/mods/a/package.json": `{"name":"a","version":"1.0.0"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(testGame, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			panic(err)
+		}
+	}
+
+	archives := map[string][]byte{
+		"/a.zip": testArchive("a", "1.1.0"),
+		"/b.zip": testArchive("b", "1.0.0"),
+	}
+	var database *httptest.Server
+	database = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mods.json" {
+			w.Write(archives[r.URL.Path])
+			return
+		}
+		fmt.Fprintf(w, `{"mods":{
+			"a":{"name":"a","description":"","version":"1.1.0","archive_link":"%[1]s/a.zip","hash":{}},
+			"b":{"name":"b","description":"","version":"1.0.0","archive_link":"%[1]s/b.zip","hash":{}}
+		}}`, database.URL)
+	}))
+
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	os.Setenv("CCMU_GAME", testGame)
+	os.Setenv("CCMU_REPOSITORIES", database.URL+"/mods.json")
+
+	code := m.Run()
+	database.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func testArchive(name, version string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	file, err := archive.Create(name + "/package.json")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(file, `{"name":"%s","version":"%s"}`, name, version)
+	if err := archive.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
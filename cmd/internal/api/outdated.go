@@ -1,11 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"flag"
 	"fmt"
 	"net/http"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
 
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
@@ -14,13 +15,18 @@ import (
 //OutdatedRequest for incoming outdated requests
 type OutdatedRequest struct {
 	Game *string `json:"game"`
+	//Changelog adds the release notes of the newer versions to the outdated mods
+	Changelog bool `json:"changelog,omitempty"`
 }
 
 //OutdatedResponse contains a list of outdated mods
 type OutdatedResponse struct {
 	Success bool                  `json:"success"`
 	Message string                `json:"message,omitempty"`
+	Code    errcode.Code          `json:"code,omitempty"`
 	Mods    []OutdatedDescription `json:"mods"`
+	//Warnings contains the release notes which could not be read
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 //OutdatedDescription contains basic information about an outdated mod
@@ -28,6 +34,9 @@ type OutdatedDescription struct {
 	Current string `json:"current"`
 	Newest  string `json:"newest"`
 	Name    string `json:"name"`
+	//Changes are the release notes of the versions since the current one, newest first.
+	//They are only set if the request asked for the changelog
+	Changes []global.Release `json:"changes,omitempty"`
 }
 
 //Outdated returns all available mods
@@ -39,42 +48,45 @@ func Outdated(w http.ResponseWriter, r *http.Request) {
 
 	setHeaders(w)
 
-	mods, err := outdated(decoder)
+	mods, warnings, err := outdated(r.Context(), decoder)
 
 	encoder := json.NewEncoder(w)
 	if err == nil {
 		encoder.Encode(&OutdatedResponse{
-			Success: true,
-			Mods:    mods,
+			Success:  true,
+			Mods:     mods,
+			Warnings: warnings,
 		})
 	} else {
+		w.WriteHeader(httpStatus(err))
 		encoder.Encode(&OutdatedResponse{
 			Success: false,
 			Message: err.Error(),
+			Code:    errcode.Of(err),
 		})
 	}
 }
 
-func outdated(decoder *json.Decoder) ([]OutdatedDescription, error) {
+func outdated(ctx context.Context, decoder *json.Decoder) ([]OutdatedDescription, []string, error) {
+	var req OutdatedRequest
 	if decoder != nil {
-		var req OutdatedRequest
 		if err := decoder.Decode(&req); err != nil {
-			return nil, fmt.Errorf("cmd/internal/api: Could not parse request body: %s", err.Error())
+			return nil, nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error())
 		}
+	}
 
-		if req.Game != nil {
-			if err := flag.Set("game", *req.Game); err != nil {
-				return nil, fmt.Errorf("cmd/internal/api: Could set game flag: %s", err.Error())
-			}
-		}
+	game, err := findGame(req.Game)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	mods, err := local.GetMods()
+	mods, err := local.GetMods(game)
 	if err != nil {
-		return nil, fmt.Errorf("cmd/internal/api: Could not list mods because of an error in %s", err.Error())
+		return nil, nil, errcode.Wrap(err, "cmd/internal/api: Could not list mods because of an error in %s", err.Error())
 	}
 
 	var res []OutdatedDescription
+	var warnings []string
 	for _, mod := range mods {
 		if out, _ := mod.Outdated(); out {
 			new, err := global.GetMod(mod.Name)
@@ -83,12 +95,20 @@ func outdated(decoder *json.Decoder) ([]OutdatedDescription, error) {
 				continue
 			}
 
-			res = append(res, OutdatedDescription{
+			description := OutdatedDescription{
 				Current: mod.Version,
 				Newest:  new.Version,
 				Name:    mod.Name,
-			})
+			}
+			if req.Changelog {
+				//Missing release notes are reported but do not hide the mod
+				description.Changes, err = new.Changes(ctx, mod.Version)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("cmd/internal/api: Could not read changelog of '%s' because an error occured in %s", mod.Name, err.Error()))
+				}
+			}
+			res = append(res, description)
 		}
 	}
-	return res, nil
+	return res, warnings, nil
 }
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+)
+
+//Route is an endpoint of the api server
+type Route struct {
+	//Path is the pattern the handler is registered with
+	Path    string
+	Handler http.HandlerFunc
+	//Protected routes require the api token for requests that are not GET requests
+	Protected  bool
+	Operations []Operation
+}
+
+//Operation documents a method of a route in the OpenAPI document
+type Operation struct {
+	//Path overrides the path of the route, e.g. to document path parameters
+	Path    string
+	Method  string
+	ID      string
+	Summary string
+	//Query lists the names of the supported query parameters
+	Query    []string
+	Request  interface{}
+	Response interface{}
+	//Status of a successful response. Defaults to 200
+	Status int
+	//Async operations return 202 with the job instead if they run in the background
+	Async bool
+	//Stream is set if the response is a stream of server-sent events
+	Stream bool
+}
+
+//Routes returns all endpoints of the api server
+func Routes() []Route {
+	return []Route{
+		{
+			Path:    "/healthz",
+			Handler: Health,
+			Operations: []Operation{
+				{Method: "GET", ID: "health", Summary: "Checks if the server is running", Response: HealthResponse{}},
+			},
+		},
+		{
+			Path:    "/api/v1/version",
+			Handler: Version,
+			Operations: []Operation{
+				{Method: "GET", ID: "version", Summary: "Returns the version of the tool", Response: VersionResponse{}},
+			},
+		},
+		{
+			Path:    "/api/v1/openapi.json",
+			Handler: OpenAPI,
+			Operations: []Operation{
+				{Method: "GET", ID: "openapi", Summary: "Returns this document"},
+			},
+		},
+		{
+			Path:      "/api/v1/install",
+			Handler:   Install,
+			Protected: true,
+			Operations: []Operation{
+				{Method: "POST", ID: "install", Summary: "Installs mods and their dependencies", Request: InstallRequest{}, Response: InstallResponse{}},
+			},
+		},
+		{
+			Path:      "/api/v1/uninstall",
+			Handler:   Uninstall,
+			Protected: true,
+			Operations: []Operation{
+				{Method: "POST", ID: "uninstall", Summary: "Removes mods", Request: UninstallRequest{}, Response: UninstallResponse{}},
+			},
+		},
+		{
+			Path:      "/api/v1/update",
+			Handler:   Update,
+			Protected: true,
+			Operations: []Operation{
+				{Method: "POST", ID: "update", Summary: "Updates mods or all outdated mods if no names are given", Request: UpdateRequest{}, Response: UpdateResponse{}},
+			},
+		},
+		{
+			Path:    "/api/v1/get/local",
+			Handler: GetLocalMods,
+			Operations: []Operation{
+				{Method: "GET", ID: "getLocalMods", Summary: "Lists installed mods", Response: LocalModsResponse{}},
+				{Method: "POST", ID: "getLocalModsOfGame", Summary: "Lists installed mods of a game", Request: LocalModsRequest{}, Response: LocalModsResponse{}},
+			},
+		},
+		{
+			Path:    "/api/v1/get/global",
+			Handler: GetGlobalMods,
+			Operations: []Operation{
+				{Method: "GET", ID: "getGlobalMods", Summary: "Lists available mods", Response: GlobalModsResponse{}},
+				{Method: "POST", ID: "getGlobalModsOfGame", Summary: "Lists available mods for a game", Request: GlobalModsRequest{}, Response: GlobalModsResponse{}},
+			},
+		},
+		{
+			Path:    "/api/v1/get/outdated",
+			Handler: Outdated,
+			Operations: []Operation{
+				{Method: "GET", ID: "getOutdated", Summary: "Lists outdated mods", Response: OutdatedResponse{}},
+				{Method: "POST", ID: "getOutdatedOfGame", Summary: "Lists outdated mods of a game and optionally their release notes", Request: OutdatedRequest{}, Response: OutdatedResponse{}},
+			},
+		},
+		{
+			Path:      "/api/v1/enable",
+			Handler:   Enable,
+			Protected: true,
+			Operations: []Operation{
+				{Method: "POST", ID: "enable", Summary: "Enables disabled mods", Request: EnableRequest{}, Response: EnableResponse{}},
+			},
+		},
+		{
+			Path:      "/api/v1/disable",
+			Handler:   Disable,
+			Protected: true,
+			Operations: []Operation{
+				{Method: "POST", ID: "disable", Summary: "Disables mods without removing them", Request: EnableRequest{}, Response: EnableResponse{}},
+			},
+		},
+		{
+			Path:    "/api/v1/history",
+			Handler: History,
+			Operations: []Operation{
+				{Method: "GET", ID: "getHistory", Summary: "Lists the operations which changed the mods of a game", Query: []string{"game", "since", "mod", "limit"}, Response: HistoryResponse{}},
+			},
+		},
+		{
+			Path:      "/api/v1/jobs",
+			Handler:   Jobs,
+			Protected: true,
+			Operations: []Operation{
+				{Method: "GET", ID: "listJobs", Summary: "Lists all known jobs", Response: JobsResponse{}},
+				{Method: "POST", ID: "submitJob", Summary: "Runs an install, update or uninstall operation in the background", Request: JobRequest{}, Response: JobResponse{}, Status: http.StatusAccepted},
+			},
+		},
+		{
+			Path:      "/api/v1/jobs/",
+			Handler:   JobByID,
+			Protected: true,
+			Operations: []Operation{
+				{Path: "/api/v1/jobs/{id}", Method: "GET", ID: "getJob", Summary: "Returns the state of a job", Response: JobResponse{}},
+				{Path: "/api/v1/jobs/{id}", Method: "DELETE", ID: "cancelJob", Summary: "Cancels a job", Response: JobResponse{}},
+				{Path: "/api/v1/jobs/{id}/events", Method: "GET", ID: "streamJobEvents", Summary: "Streams the events of a job", Stream: true},
+			},
+		},
+		{
+			Path:    "/api/v2/mods",
+			Handler: ModsV2,
+			Operations: []Operation{
+				{Method: "GET", ID: "listMods", Summary: "Lists available mods", Query: []string{"q", "offset", "limit"}, Response: ModList{}},
+			},
+		},
+		{
+			Path:    "/api/v2/mods/",
+			Handler: ModV2,
+			Operations: []Operation{
+				{Path: "/api/v2/mods/{name}", Method: "GET", ID: "getMod", Summary: "Returns an available mod", Response: ModResource{}},
+			},
+		},
+		{
+			Path:    "/api/v2/installed",
+			Handler: InstalledV2,
+			Operations: []Operation{
+				{Method: "GET", ID: "listInstalled", Summary: "Lists installed mods of a game", Query: []string{"game", "q", "outdated", "offset", "limit"}, Response: InstalledModList{}},
+			},
+		},
+		{
+			Path:      "/api/v2/installed/",
+			Handler:   InstalledModV2,
+			Protected: true,
+			Operations: []Operation{
+				{Path: "/api/v2/installed/{name}", Method: "GET", ID: "getInstalled", Summary: "Returns an installed mod", Query: []string{"game"}, Response: InstalledModResource{}},
+				{Path: "/api/v2/installed/{name}", Method: "PUT", ID: "putInstalled", Summary: "Installs a mod or updates it to the newest version", Query: []string{"game", "async", "dryRun"}, Response: OperationResult{}, Async: true},
+				{Path: "/api/v2/installed/{name}", Method: "DELETE", ID: "deleteInstalled", Summary: "Removes a mod", Query: []string{"game", "async", "dryRun"}, Response: OperationResult{}, Async: true},
+				{Path: "/api/v2/installed/{name}:enable", Method: "POST", ID: "enableInstalled", Summary: "Enables a mod", Query: []string{"game", "async", "dryRun"}, Response: OperationResult{}, Async: true},
+				{Path: "/api/v2/installed/{name}:disable", Method: "POST", ID: "disableInstalled", Summary: "Disables a mod without removing it", Query: []string{"game", "async", "dryRun"}, Response: OperationResult{}, Async: true},
+			},
+		},
+		{
+			Path:      "/api/v2/installed:update",
+			Handler:   UpdateInstalledV2,
+			Protected: true,
+			Operations: []Operation{
+				{Method: "POST", ID: "updateInstalled", Summary: "Updates the given or all outdated mods", Query: []string{"game", "async", "dryRun"}, Request: UpdateInstalledRequest{}, Response: OperationResult{}, Async: true},
+			},
+		},
+		{
+			Path:      "/api/v2/games",
+			Handler:   GamesV2,
+			Protected: true,
+			Operations: []Operation{
+				{Method: "GET", ID: "listGames", Summary: "Lists the registered games", Response: GameList{}},
+				{Method: "POST", ID: "registerGame", Summary: "Registers a game", Request: config.Game{}, Response: config.Game{}, Status: http.StatusCreated},
+			},
+		},
+		{
+			Path:      "/api/v2/games/",
+			Handler:   GameV2,
+			Protected: true,
+			Operations: []Operation{
+				{Path: "/api/v2/games/{id}", Method: "GET", ID: "getGame", Summary: "Returns a registered game", Response: config.Game{}},
+				{Path: "/api/v2/games/{id}", Method: "DELETE", ID: "deleteGame", Summary: "Forgets a registered game", Status: http.StatusNoContent},
+			},
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+)
+
+//Security restricts which clients may use the api server
+type Security struct {
+	token   string
+	origins []string
+}
+
+//LoadSecurity reads the token and allowed origins from the user configuration and the environment.
+//A token is generated and saved if there is none yet
+func LoadSecurity() (*Security, error) {
+	cfg, err := config.Effective("")
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.APIToken == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+
+		saved, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		saved.APIToken = hex.EncodeToString(buf)
+		if err := saved.Save(); err != nil {
+			return nil, err
+		}
+		cfg.APIToken = saved.APIToken
+	}
+
+	return &Security{
+		token:   cfg.APIToken,
+		origins: cfg.APIOrigins,
+	}, nil
+}
+
+//Allow only applies the origin restrictions to the handler
+func (s *Security) Allow(handler http.HandlerFunc) http.HandlerFunc {
+	return s.wrap(handler, false)
+}
+
+//Protect additionally requires the api token for every request that is not a GET request
+func (s *Security) Protect(handler http.HandlerFunc) http.HandlerFunc {
+	return s.wrap(handler, true)
+}
+
+func (s *Security) wrap(handler http.HandlerFunc, protected bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !s.originAllowed(origin) {
+				writeError(w, errcode.New(errcode.Forbidden, "cmd/internal/api: Origin '%s' is not allowed", origin))
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			w.Header().Add("Vary", "Origin")
+		}
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if protected && r.Method != "GET" && r.Method != "HEAD" && !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, errcode.New(errcode.Unauthorized, "cmd/internal/api: Missing or invalid api token"))
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func (s *Security) originAllowed(origin string) bool {
+	for _, allowed := range s.origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Security) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+
+	token := strings.TrimPrefix(auth, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//HealthResponse tells clients that the server is running
+type HealthResponse struct {
+	Success bool   `json:"success"`
+	Status  string `json:"status"`
+}
+
+//VersionResponse contains the version of the tool
+type VersionResponse struct {
+	Success bool   `json:"success"`
+	Version string `json:"version"`
+}
+
+//Health reports that the server is able to handle requests
+func Health(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w)
+	json.NewEncoder(w).Encode(&HealthResponse{
+		Success: true,
+		Status:  "ok",
+	})
+}
+
+//Version returns the version of the tool
+func Version(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w)
+	json.NewEncoder(w).Encode(&VersionResponse{
+		Success: true,
+		Version: cmd.Version,
+	})
+}
+
+//FreshMods drops the indexes of the installed mods which were changed by other programs since the last request.
+//Indexes which did not change are kept so operations which are still running can use them
+func FreshMods(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		local.RefreshAll()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+//accessLog is written as a single JSON line for every request
+type accessLog struct {
+	Time     string  `json:"time"`
+	Remote   string  `json:"remote"`
+	Method   string  `json:"method"`
+	Path     string  `json:"path"`
+	Status   int     `json:"status"`
+	Bytes    int64   `json:"bytes"`
+	Duration float64 `json:"durationMs"`
+}
+
+//LogRequests writes an access log entry to out after every request
+func LogRequests(out io.Writer, handler http.Handler) http.Handler {
+	encoder := json.NewEncoder(out)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		handler.ServeHTTP(rec, r)
+
+		encoder.Encode(&accessLog{
+			Time:     start.UTC().Format(time.RFC3339),
+			Remote:   r.RemoteAddr,
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			Status:   rec.status,
+			Bytes:    rec.bytes,
+			Duration: float64(time.Since(start)) / float64(time.Millisecond),
+		})
+	})
+}
+
+//statusRecorder remembers the status and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(p []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(p)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+//Flush keeps event streams working through the recorder
+func (rec *statusRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
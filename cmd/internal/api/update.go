@@ -1,38 +1,43 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"flag"
-	"fmt"
 	"net/http"
 
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 )
 
 //UpdateRequest for incoming update requests
 type UpdateRequest struct {
 	Game  *string  `json:"game"`
 	Names []string `json:"names"`
+	//DryRun only returns the planned changes in the stats
+	DryRun bool `json:"dryRun,omitempty"`
+	//Changelog adds the release notes of the new versions to the planned changes of a dry run
+	Changelog bool `json:"changelog,omitempty"`
 }
 
 //UpdateResponse for update requests
 type UpdateResponse struct {
-	Success bool       `json:"success"`
-	Message string     `json:"message,omitempty"`
-	Stats   *cmd.Stats `json:"stats,omitempty"`
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Code    errcode.Code `json:"code,omitempty"`
+	Stats   *cmd.Stats   `json:"stats,omitempty"`
 }
 
 //Update a mod via api request
 func Update(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method Not Allowed", 405)
+		methodNotAllowed(w, r)
 		return
 	}
 
 	setHeaders(w)
 
 	decoder := json.NewDecoder(r.Body)
-	stats, err := update(decoder)
+	stats, err := update(r.Context(), decoder)
 
 	encoder := json.NewEncoder(w)
 	if err == nil {
@@ -41,25 +46,21 @@ func Update(w http.ResponseWriter, r *http.Request) {
 			Stats:   stats,
 		})
 	} else {
+		w.WriteHeader(httpStatus(err))
 		encoder.Encode(&UpdateResponse{
 			Success: false,
 			Message: err.Error(),
+			Code:    errcode.Of(err),
 			Stats:   stats,
 		})
 	}
 }
 
-func update(decoder *json.Decoder) (*cmd.Stats, error) {
+func update(ctx context.Context, decoder *json.Decoder) (*cmd.Stats, error) {
 	var req UpdateRequest
 	if err := decoder.Decode(&req); err != nil {
-		return nil, fmt.Errorf("cmd/internal/api: Could not parse request body: %s", err.Error())
+		return nil, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error())
 	}
 
-	if req.Game != nil {
-		if err := flag.Set("game", *req.Game); err != nil {
-			return nil, fmt.Errorf("cmd/internal/api: Could set game flag: %s", err.Error())
-		}
-	}
-
-	return cmd.Update(req.Names)
+	return runJob(ctx, JobRequest{Operation: "update", Game: req.Game, Names: req.Names, DryRun: req.DryRun, Changelog: req.Changelog})
 }
@@ -0,0 +1,466 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//ModResource is a mod available in the mod database
+type ModResource struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Version     string         `json:"version"`
+	License     *string        `json:"license,omitempty"`
+	Pages       []PageResource `json:"pages"`
+	ArchiveLink string         `json:"archiveLink"`
+	Sha256      string         `json:"sha256,omitempty"`
+}
+
+//PageResource links to a website of a mod
+type PageResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+//InstalledModResource is a mod installed in a game
+type InstalledModResource struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Path         string            `json:"path"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+	Newest       string            `json:"newest,omitempty"`
+	Outdated     bool              `json:"outdated"`
+	Enabled      bool              `json:"enabled"`
+}
+
+//ModList is a page of available mods
+type ModList struct {
+	Items  []ModResource `json:"items"`
+	Total  int           `json:"total"`
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+}
+
+//InstalledModList is a page of installed mods
+type InstalledModList struct {
+	Items  []InstalledModResource `json:"items"`
+	Total  int                    `json:"total"`
+	Offset int                    `json:"offset"`
+	Limit  int                    `json:"limit"`
+}
+
+//GameList contains the registered games
+type GameList struct {
+	Items []config.Game `json:"items"`
+}
+
+//UpdateInstalledRequest selects the mods to update. All outdated mods are updated if there are no names
+type UpdateInstalledRequest struct {
+	Names []string `json:"names"`
+}
+
+//OperationResult is returned after a mod was installed, updated or removed.
+//Only the job is set if the operation runs asynchronously
+type OperationResult struct {
+	Stats *cmd.Stats `json:"stats,omitempty"`
+	Job   *Job       `json:"job,omitempty"`
+}
+
+//ModsV2 lists available mods. Supports the q, offset and limit query parameters
+func ModsV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	db, err := global.FetchModData()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	query := strings.ToLower(r.URL.Query().Get("q"))
+	items := []ModResource{}
+	for _, mod := range db.Mods {
+		if query != "" && !strings.Contains(strings.ToLower(mod.Name+" "+mod.Description), query) {
+			continue
+		}
+		items = append(items, modResource(mod))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	start, end, offset, limit, err := page(r, len(items))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeResource(w, r, http.StatusOK, &ModList{
+		Items:  items[start:end],
+		Total:  len(items),
+		Offset: offset,
+		Limit:  limit,
+	})
+}
+
+//ModV2 returns a single available mod
+func ModV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	mod, err := global.GetMod(strings.TrimPrefix(r.URL.Path, "/api/v2/mods/"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, modResource(mod))
+}
+
+//InstalledV2 lists the installed mods of a game. Supports the game, q, outdated, offset and limit query parameters
+func InstalledV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	game, err := requestGame(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	mods, err := local.GetMods(game)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	query := strings.ToLower(r.URL.Query().Get("q"))
+	onlyOutdated := r.URL.Query().Get("outdated") == "true"
+	items := []InstalledModResource{}
+	for _, mod := range mods {
+		if query != "" && !strings.Contains(strings.ToLower(mod.Name), query) {
+			continue
+		}
+
+		res := installedModResource(mod)
+		if onlyOutdated && !res.Outdated {
+			continue
+		}
+		items = append(items, res)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	start, end, offset, limit, err := page(r, len(items))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeResource(w, r, http.StatusOK, &InstalledModList{
+		Items:  items[start:end],
+		Total:  len(items),
+		Offset: offset,
+		Limit:  limit,
+	})
+}
+
+//InstalledModV2 returns, installs or updates (PUT) and removes (DELETE) a mod of a game.
+//POST requests to {name}:enable and {name}:disable toggle the mod
+func InstalledModV2(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/v2/installed/")
+	game, err := requestGame(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		action := name[i+1:]
+		name = name[:i]
+		if r.Method != "POST" {
+			methodNotAllowed(w, r)
+			return
+		}
+		if action != "enable" && action != "disable" {
+			writeError(w, errcode.New(errcode.NotFound, "cmd/internal/api: Unknown action '%s'", action))
+			return
+		}
+		if _, err := local.GetMod(game, name); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		runOperation(w, r, action, []string{name})
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		mod, err := local.GetMod(game, name)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeResource(w, r, http.StatusOK, installedModResource(mod))
+	case "PUT":
+		if _, err := local.GetMod(game, name); err == nil {
+			runOperation(w, r, "update", []string{name})
+		} else {
+			runOperation(w, r, "install", []string{name})
+		}
+	case "DELETE":
+		if _, err := local.GetMod(game, name); err != nil {
+			writeError(w, err)
+			return
+		}
+		runOperation(w, r, "uninstall", []string{name})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+//UpdateInstalledV2 updates the given mods or all outdated mods of a game
+func UpdateInstalledV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	var req UpdateInstalledRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error()))
+		return
+	}
+
+	runOperation(w, r, "update", req.Names)
+}
+
+//GamesV2 lists (GET) and registers (POST) games
+func GamesV2(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		cfg, err := config.Load()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		items := cfg.Games
+		if items == nil {
+			items = []config.Game{}
+		}
+		writeResource(w, r, http.StatusOK, &GameList{Items: items})
+	case "POST":
+		var game config.Game
+		if err := json.NewDecoder(r.Body).Decode(&game); err != nil {
+			writeError(w, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Could not parse request body: %s", err.Error()))
+			return
+		}
+
+		created, err := cmd.AddGame(game)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Location", "/api/v2/games/"+created.ID)
+		writeResource(w, r, http.StatusCreated, created)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+//GameV2 returns (GET) or forgets (DELETE) a registered game
+func GameV2(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/v2/games/")
+	cfg, err := config.Load()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	game := cfg.FindGame(id)
+	if game == nil {
+		writeError(w, errcode.New(errcode.GameNotFound, "cmd/internal/api: Could not find game '%s'", id))
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeResource(w, r, http.StatusOK, game)
+	case "DELETE":
+		if err := cmd.RemoveGame(id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+//gamePath returns the path of the game selected by the game query parameter or nil if there is none
+func gamePath(r *http.Request) (*string, error) {
+	id := r.URL.Query().Get("game")
+	if id == "" {
+		return nil, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	game := cfg.FindGame(id)
+	if game == nil {
+		return nil, errcode.New(errcode.GameNotFound, "cmd/internal/api: Could not find game '%s'", id)
+	}
+	return &game.Path, nil
+}
+
+//requestGame returns the folder of the game selected by the request or the game of the server.
+//It does not change the game flag since other requests and jobs may run at the same time
+func requestGame(r *http.Request) (string, error) {
+	path, err := gamePath(r)
+	if err != nil {
+		return "", err
+	}
+	return findGame(path)
+}
+
+//runOperation executes the operation as a job so it does not interfere with other jobs.
+//The job is returned right away if the async query parameter is set
+func runOperation(w http.ResponseWriter, r *http.Request, operation string, names []string) {
+	game, err := gamePath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	job, err := startJob(JobRequest{
+		Operation: operation,
+		Game:      game,
+		Names:     names,
+		DryRun:    r.URL.Query().Get("dryRun") == "true",
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("async") == "true" {
+		w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+		writeResource(w, r, http.StatusAccepted, &OperationResult{Job: job.snapshot()})
+		return
+	}
+
+	stats, err := waitJob(r.Context(), job)
+	if r.Context().Err() != nil {
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, &OperationResult{Stats: stats})
+}
+
+//page returns the bounds of the requested page of a list with total items
+func page(r *http.Request, total int) (start, end, offset, limit int, err error) {
+	query := r.URL.Query()
+	if value := query.Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return 0, 0, 0, 0, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Invalid offset '%s'", value)
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			return 0, 0, 0, 0, errcode.New(errcode.InvalidRequest, "cmd/internal/api: Invalid limit '%s'", value)
+		}
+	}
+
+	start = offset
+	if start > total {
+		start = total
+	}
+	end = total
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	return start, end, offset, limit, nil
+}
+
+//writeResource encodes the value and answers GET requests with 304 if the client already has it
+func writeResource(w http.ResponseWriter, r *http.Request, status int, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setHeaders(w)
+	if r.Method == "GET" && status == http.StatusOK {
+		sum := sha256.Sum256(data)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+
+		for _, match := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+			match = strings.TrimSpace(match)
+			if match == etag || match == "*" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+
+	w.WriteHeader(status)
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+func modResource(mod global.Mod) ModResource {
+	res := ModResource{
+		Name:        mod.Name,
+		Description: mod.Description,
+		Version:     mod.Version,
+		License:     mod.License,
+		Pages:       []PageResource{},
+		ArchiveLink: mod.ArchiveLink,
+		Sha256:      mod.Hash.Sha256,
+	}
+	for _, page := range mod.Page {
+		res.Pages = append(res.Pages, PageResource{Name: page.Name, URL: page.URL})
+	}
+	return res
+}
+
+func installedModResource(mod local.Mod) InstalledModResource {
+	res := InstalledModResource{
+		Name:         mod.Name,
+		Version:      mod.Version,
+		Path:         mod.BasePath,
+		Dependencies: mod.Dependencies,
+		Enabled:      mod.Enabled,
+	}
+
+	if newest, err := global.GetMod(mod.Name); err == nil {
+		res.Newest = newest.Version
+		res.Outdated, _ = mod.Outdated()
+	}
+	return res
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+//Config contains the settings of the user
+type Config struct {
+	//DefaultGame is the id of a registered game or the path of the game folder used if no game is given
+	DefaultGame string `json:"game,omitempty"`
+	//Repositories are the urls of the mod databases. Earlier repositories take precedence
+	Repositories []string `json:"repositories,omitempty"`
+	//Cache is the directory containing downloads
+	Cache string `json:"cache,omitempty"`
+	//Parallel is the amount of concurrent downloads
+	Parallel int `json:"parallel,omitempty"`
+	//Progress selects how progress is printed: auto, plain or none
+	Progress string `json:"progress,omitempty"`
+	//ModDirs are additional folders the loader reads mods from. Relative paths are relative to the game folder
+	ModDirs []string `json:"modDirs,omitempty"`
+	//APIToken has to be sent by clients of the api server to change mods
+	APIToken string `json:"apiToken,omitempty"`
+	//APIOrigins lists the web origins that may use the api server. "*" allows every origin
+	APIOrigins []string `json:"apiOrigins,omitempty"`
+	//Games are the known installations of the game
+	Games []Game `json:"games,omitempty"`
+	//SnapshotLimit is the amount of snapshots kept per game
+	SnapshotLimit int `json:"snapshotLimit,omitempty"`
+	//SnapshotDays is the amount of days after which snapshots are removed
+	SnapshotDays int `json:"snapshotDays,omitempty"`
+	//ModIndex turns the index of installed mods in the cache on or off
+	ModIndex string `json:"modIndex,omitempty"`
+	//Proxy is the url of the proxy used for downloads. The proxy flag takes precedence
+	Proxy string `json:"proxy,omitempty"`
+}
+
+//Game is a registered installation of the game
+type Game struct {
+	//ID is a unique name of the installation
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	//Source is the store the game was discovered in. It is empty for games added by hand
+	Source string `json:"source,omitempty"`
+}
+
+//FindGame returns the registered game with the id or nil if there is none
+func (cfg *Config) FindGame(id string) *Game {
+	for i := range cfg.Games {
+		if cfg.Games[i].ID == id {
+			return &cfg.Games[i]
+		}
+	}
+	return nil
+}
+
+//FindGamePath returns the registered game in the folder or nil if there is none
+func (cfg *Config) FindGamePath(path string) *Game {
+	for i := range cfg.Games {
+		if cfg.Games[i].Path == path {
+			return &cfg.Games[i]
+		}
+	}
+	return nil
+}
+
+//ResolveGame returns the path of the registered game with the id or the game itself if it is not an id
+func (cfg *Config) ResolveGame(game string) string {
+	if registered := cfg.FindGame(game); registered != nil {
+		return registered.Path
+	}
+	return game
+}
+
+//Dir returns the directory containing the configuration of the tool
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ccmu"), nil
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "ccmu"), nil
+		}
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "Library", "Application Support", "ccmu"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "ccmu"), nil
+}
+
+//GameKey returns a name for the game folder which can be used to store data of the game
+func GameKey(game string) (string, error) {
+	abs, err := filepath.Abs(game)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256([]byte(abs))
+	return hex.EncodeToString(hash[:8]), nil
+}
+
+//Path returns the location of the configuration file
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+//GamePath returns the location of the configuration file which overrides the settings for the game
+func GamePath(game string) string {
+	return filepath.Join(game, "ccmu.json")
+}
+
+//Load reads the configuration file. A missing file results in an empty configuration
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return read(path)
+}
+
+//LoadGame reads the configuration file of the game. A missing file results in an empty configuration
+func LoadGame(game string) (*Config, error) {
+	return read(GamePath(game))
+}
+
+//Effective returns the settings which apply to the game. The settings of the user are
+//overridden by the configuration file of the game and then by the environment.
+//The game may be empty to only use the settings of the user
+func Effective(game string) (*Config, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if game != "" {
+		override, err := LoadGame(game)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range Keys {
+			if key.PerGame && key.IsSet(override) {
+				key.Set(cfg, key.Get(override))
+			}
+		}
+	}
+
+	for _, key := range Keys {
+		if value, ok := os.LookupEnv(key.Env); ok {
+			if err := key.Set(cfg, value); err != nil {
+				return nil, fmt.Errorf("cmd/internal: Invalid value of %s: %s", key.Env, err.Error())
+			}
+		}
+	}
+	return cfg, nil
+}
+
+func read(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+//Save writes the configuration file. It is only readable by the user since it contains the api token
+func (cfg *Config) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return cfg.write(path, 0600)
+}
+
+//SaveGame writes the configuration file of the game
+func (cfg *Config) SaveGame(game string) error {
+	return cfg.write(GamePath(game), 0644)
+}
+
+func (cfg *Config) write(path string, perm os.FileMode) error {
+	data, err := json.MarshalIndent(cfg, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, perm)
+}
+
+//CacheDir returns the directory containing downloads
+func (cfg *Config) CacheDir() (string, error) {
+	if cfg.Cache != "" {
+		return cfg.Cache, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ccmu"), nil
+}
+
+//UsesModIndex checks if the installed mods are indexed in the cache
+func (cfg *Config) UsesModIndex() bool {
+	return cfg.ModIndex != "off"
+}
+
+func defaultCacheDir() string {
+	dir, _ := (&Config{}).CacheDir()
+	return dir
+}
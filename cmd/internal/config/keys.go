@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//Defaults of the settings
+const (
+	DefaultRepository = "https://raw.githubusercontent.com/CCDirectLink/CCModDB/master/mods.json"
+	DefaultParallel   = 4
+	DefaultProgress   = "auto"
+	DefaultModIndex   = "on"
+)
+
+//Key is a setting which can be read and changed by its name
+type Key struct {
+	Name        string
+	Description string
+	//Env is the environment variable overriding the setting
+	Env string
+	//Default is shown if the setting is not set
+	Default string
+	//PerGame is true if the setting may be overridden by the configuration file of a game
+	PerGame bool
+
+	get func(cfg *Config) string
+	set func(cfg *Config, value string) error
+}
+
+//Keys are all settings in the order they are listed in
+var Keys = []*Key{
+	{
+		Name:        "game",
+		Description: "Path of the game folder used if no game is given",
+		Env:         "CCMU_GAME",
+		get:         func(cfg *Config) string { return cfg.DefaultGame },
+		set:         func(cfg *Config, value string) error { cfg.DefaultGame = value; return nil },
+	},
+	{
+		Name:        "repositories",
+		Description: "Comma separated urls of the mod databases",
+		Env:         "CCMU_REPOSITORIES",
+		Default:     DefaultRepository,
+		get:         func(cfg *Config) string { return strings.Join(cfg.Repositories, ",") },
+		set:         func(cfg *Config, value string) error { cfg.Repositories = split(value); return nil },
+	},
+	{
+		Name:        "cache",
+		Description: "Directory containing downloads",
+		Env:         "CCMU_CACHE",
+		Default:     defaultCacheDir(),
+		PerGame:     true,
+		get:         func(cfg *Config) string { return cfg.Cache },
+		set:         func(cfg *Config, value string) error { cfg.Cache = value; return nil },
+	},
+	{
+		Name:        "parallel",
+		Description: "Amount of concurrent downloads",
+		Env:         "CCMU_PARALLEL",
+		Default:     strconv.Itoa(DefaultParallel),
+		PerGame:     true,
+		get:         func(cfg *Config) string { return itoa(cfg.Parallel) },
+		set:         func(cfg *Config, value string) error { return atoi(value, &cfg.Parallel) },
+	},
+	{
+		Name:        "progress",
+		Description: "How progress is printed: auto, plain or none",
+		Env:         "CCMU_PROGRESS",
+		Default:     DefaultProgress,
+		PerGame:     true,
+		get:         func(cfg *Config) string { return cfg.Progress },
+		set: func(cfg *Config, value string) error {
+			switch value {
+			case "", "auto", "plain", "none":
+				cfg.Progress = value
+				return nil
+			}
+			return fmt.Errorf("'%s' is not one of auto, plain or none", value)
+		},
+	},
+	{
+		Name:        "modDirs",
+		Description: "Comma separated additional folders the loader reads mods from",
+		Env:         "CCMU_MOD_DIRS",
+		PerGame:     true,
+		get:         func(cfg *Config) string { return strings.Join(cfg.ModDirs, ",") },
+		set:         func(cfg *Config, value string) error { cfg.ModDirs = split(value); return nil },
+	},
+	{
+		Name:        "modIndex",
+		Description: "Whether installed mods are indexed in the cache so unchanged manifests are not read again: on or off",
+		Env:         "CCMU_MOD_INDEX",
+		Default:     DefaultModIndex,
+		PerGame:     true,
+		get:         func(cfg *Config) string { return cfg.ModIndex },
+		set: func(cfg *Config, value string) error {
+			switch value {
+			case "", "on", "off":
+				cfg.ModIndex = value
+				return nil
+			}
+			return fmt.Errorf("'%s' is not one of on or off", value)
+		},
+	},
+	{
+		Name:        "proxy",
+		Description: "Url of the proxy used for downloads. The environment variables HTTP_PROXY and HTTPS_PROXY are used if it is not set",
+		Env:         "CCMU_PROXY",
+		get:         func(cfg *Config) string { return cfg.Proxy },
+		set: func(cfg *Config, value string) error {
+			if value != "" {
+				if _, err := url.Parse(value); err != nil {
+					return fmt.Errorf("'%s' is not a valid url", value)
+				}
+			}
+			cfg.Proxy = value
+			return nil
+		},
+	},
+	{
+		Name:        "apiToken",
+		Description: "Token which clients of the api server have to send to change mods",
+		Env:         "CCMU_API_TOKEN",
+		get:         func(cfg *Config) string { return cfg.APIToken },
+		set:         func(cfg *Config, value string) error { cfg.APIToken = value; return nil },
+	},
+	{
+		Name:        "apiOrigins",
+		Description: "Comma separated web origins which may use the api server",
+		Env:         "CCMU_API_ORIGINS",
+		get:         func(cfg *Config) string { return strings.Join(cfg.APIOrigins, ",") },
+		set:         func(cfg *Config, value string) error { cfg.APIOrigins = split(value); return nil },
+	},
+	{
+		Name:        "snapshotLimit",
+		Description: "Amount of snapshots kept per game",
+		Env:         "CCMU_SNAPSHOT_LIMIT",
+		Default:     "10",
+		PerGame:     true,
+		get:         func(cfg *Config) string { return itoa(cfg.SnapshotLimit) },
+		set:         func(cfg *Config, value string) error { return atoi(value, &cfg.SnapshotLimit) },
+	},
+	{
+		Name:        "snapshotDays",
+		Description: "Days after which snapshots are removed",
+		Env:         "CCMU_SNAPSHOT_DAYS",
+		Default:     "30",
+		PerGame:     true,
+		get:         func(cfg *Config) string { return itoa(cfg.SnapshotDays) },
+		set:         func(cfg *Config, value string) error { return atoi(value, &cfg.SnapshotDays) },
+	},
+}
+
+//FindKey returns the setting with the name or nil if there is none
+func FindKey(name string) *Key {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key
+		}
+	}
+	return nil
+}
+
+//Get returns the value of the setting as text. It is empty if the setting is not set
+func (key *Key) Get(cfg *Config) string {
+	return key.get(cfg)
+}
+
+//Set parses and changes the setting. An empty value resets it to its default
+func (key *Key) Set(cfg *Config, value string) error {
+	return key.set(cfg, strings.TrimSpace(value))
+}
+
+//IsSet checks if the setting has a value in the configuration
+func (key *Key) IsSet(cfg *Config) bool {
+	return key.get(cfg) != ""
+}
+
+func split(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func itoa(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+func atoi(value string, dst *int) error {
+	if value == "" {
+		*dst = 0
+		return nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("'%s' is not a positive number", value)
+	}
+	*dst = n
+	return nil
+}
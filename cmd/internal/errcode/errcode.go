@@ -0,0 +1,69 @@
+package errcode
+
+import (
+	"context"
+	"fmt"
+)
+
+//Code identifies the kind of an error so clients can react to it
+type Code string
+
+//Known error codes
+const (
+	Unknown             Code = "UNKNOWN"
+	InvalidRequest      Code = "INVALID_REQUEST"
+	MethodNotAllowed    Code = "METHOD_NOT_ALLOWED"
+	Unauthorized        Code = "UNAUTHORIZED"
+	Forbidden           Code = "FORBIDDEN"
+	NotFound            Code = "NOT_FOUND"
+	GameNotFound        Code = "GAME_NOT_FOUND"
+	ModNotFound         Code = "MOD_NOT_FOUND"
+	NotInstalled        Code = "NOT_INSTALLED"
+	AlreadyInstalled    Code = "ALREADY_INSTALLED"
+	AlreadyExists       Code = "ALREADY_EXISTS"
+	InvalidMod          Code = "INVALID_MOD"
+	DependencyConflict  Code = "DEPENDENCY_CONFLICT"
+	DatabaseUnavailable Code = "DATABASE_UNAVAILABLE"
+	DownloadFailed      Code = "DOWNLOAD_FAILED"
+	HashMismatch        Code = "HASH_MISMATCH"
+	Canceled            Code = "CANCELED"
+	Unavailable         Code = "UNAVAILABLE"
+)
+
+//Error is an error with a code
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+//New returns an error with the code and the formatted message
+func New(code Code, format string, a ...interface{}) error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+//Wrap returns an error with the formatted message that keeps the code of err
+func Wrap(err error, format string, a ...interface{}) error {
+	return New(Of(err), format, a...)
+}
+
+//Of returns the code of the error or Unknown if it has none
+func Of(err error) Code {
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case *Error:
+		return e.Code
+	}
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return Canceled
+	}
+	return Unknown
+}
@@ -1,12 +1,13 @@
 package global
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"net/http"
-)
 
-const link = "https://raw.githubusercontent.com/CCDirectLink/CCModDB/master/mods.json"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/web"
+)
 
 //CCModDb contains data about mods
 type CCModDb struct {
@@ -30,24 +31,68 @@ type Mod struct {
 	Dir     *struct {
 		Any string `json:"any"`
 	} `json:"dir"`
+	//Changelog contains the release notes of the versions of the mod
+	Changelog []Release `json:"changelog,omitempty"`
+	//ChangelogLink is the url of the changelog if the mod database does not contain it
+	ChangelogLink string `json:"changelog_link,omitempty"`
 }
 
 var data *CCModDb
 
-//FetchModData from CCModDb
+//FetchModData from the configured repositories. Mods of earlier repositories take precedence
 func FetchModData() (*CCModDb, error) {
 	if data != nil {
 		return data, nil
 	}
 
-	res, err := http.Get(link)
+	repositories, err := repositories()
+	if err != nil {
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd/internal: Could not read repositories: %s", err.Error())
+	}
+
+	db := &CCModDb{Mods: map[string]Mod{}}
+	for _, link := range repositories {
+		repo, err := fetch(link)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, mod := range repo.Mods {
+			if _, exists := db.Mods[key]; !exists {
+				db.Mods[key] = mod
+			}
+		}
+	}
+
+	data = db
+	return data, nil
+}
+
+//repositories returns the urls of the mod databases. They are shared by all games since the data is only fetched once
+func repositories() ([]string, error) {
+	cfg, err := config.Effective("")
 	if err != nil {
 		return nil, err
 	}
 
-	data = &CCModDb{}
-	err = json.NewDecoder(res.Body).Decode(data)
-	return data, err
+	if len(cfg.Repositories) == 0 {
+		return []string{config.DefaultRepository}, nil
+	}
+	return cfg.Repositories, nil
+}
+
+func fetch(link string) (*CCModDb, error) {
+	res, err := web.Get(context.Background(), link)
+	if err != nil {
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd/internal: Could not download mod data: %s", err.Error())
+	}
+	defer res.Body.Close()
+
+	db := &CCModDb{}
+	if err := json.NewDecoder(res.Body).Decode(db); err != nil {
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd/internal: Could not parse mod data from '%s': %s", link, err.Error())
+	}
+	return db, nil
 }
 
 //GetMod returns the ccmoddb mod by name
@@ -62,7 +107,7 @@ func GetMod(name string) (Mod, error) {
 			return mod, nil
 		}
 	}
-	return Mod{}, fmt.Errorf("cmd/internal: Could not find mod '%s'", name)
+	return Mod{}, errcode.New(errcode.ModNotFound, "cmd/internal: Could not find mod '%s'", name)
 }
 
 func modKnown(name string) (bool, error) {
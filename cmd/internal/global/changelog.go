@@ -0,0 +1,159 @@
+package global
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/web"
+	"github.com/Masterminds/semver"
+)
+
+//Release contains the release notes of a version of a mod
+type Release struct {
+	Version string `json:"version"`
+	Date    string `json:"date,omitempty"`
+	Notes   string `json:"notes"`
+}
+
+var (
+	linkedMutex sync.Mutex
+	linked      = map[string][]Release{}
+)
+
+//Changes returns the releases of the mod which are newer than the given version up to the newest one, newest first.
+//The release notes are either part of the mod database or downloaded from the changelog link of the mod
+func (mod Mod) Changes(ctx context.Context, from string) ([]Release, error) {
+	releases := mod.Changelog
+	if len(releases) == 0 && mod.ChangelogLink != "" {
+		var err error
+		releases, err = fetchChangelog(ctx, mod.ChangelogLink)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := semver.NewVersion(from)
+	if err != nil {
+		return nil, err
+	}
+	newest, err := semver.NewVersion(mod.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	type versioned struct {
+		version *semver.Version
+		release Release
+	}
+	var found []versioned
+	for _, release := range releases {
+		version, err := semver.NewVersion(release.Version)
+		if err != nil || !version.GreaterThan(current) || version.GreaterThan(newest) {
+			continue
+		}
+		found = append(found, versioned{version, release})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].version.GreaterThan(found[j].version)
+	})
+
+	changes := []Release{}
+	for _, v := range found {
+		changes = append(changes, v.release)
+	}
+	return changes, nil
+}
+
+//fetchChangelog downloads a linked changelog once per run. It is either a JSON list of releases or
+//a Markdown file with a heading for every version like the ones of keepachangelog.com
+func fetchChangelog(ctx context.Context, link string) ([]Release, error) {
+	linkedMutex.Lock()
+	releases, ok := linked[link]
+	linkedMutex.Unlock()
+	if ok {
+		return releases, nil
+	}
+
+	res, err := web.Get(ctx, link)
+	if err != nil {
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd/internal: Could not download changelog: %s", err.Error())
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd/internal: Could not download changelog: %s", err.Error())
+	}
+
+	if text := strings.TrimSpace(string(data)); strings.HasPrefix(text, "[") {
+		if err := json.Unmarshal(data, &releases); err != nil {
+			return nil, errcode.New(errcode.DatabaseUnavailable, "cmd/internal: Could not parse changelog from '%s': %s", link, err.Error())
+		}
+	} else {
+		releases = parseMarkdownChangelog(text)
+	}
+
+	linkedMutex.Lock()
+	linked[link] = releases
+	linkedMutex.Unlock()
+	return releases, nil
+}
+
+var (
+	headingVersion = regexp.MustCompile(`v?(\d+\.\d+\.\d+[0-9A-Za-z.+-]*)`)
+	headingDate    = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+)
+
+//parseMarkdownChangelog splits a Markdown changelog into the sections below headings containing a version.
+//Deeper headings belong to the section while others like "Unreleased" end it
+func parseMarkdownChangelog(text string) []Release {
+	var releases []Release
+	var release *Release
+	var notes []string
+	level := 0
+
+	finish := func() {
+		if release != nil {
+			release.Notes = strings.TrimSpace(strings.Join(notes, "\n"))
+			releases = append(releases, *release)
+		}
+		release = nil
+		notes = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "#") {
+			if release != nil {
+				notes = append(notes, line)
+			}
+			continue
+		}
+
+		depth := len(line) - len(strings.TrimLeft(line, "#"))
+		version := headingVersion.FindStringSubmatch(line)
+		if version == nil {
+			if release != nil && depth > level {
+				//Subsections like "### Fixed" belong to the release
+				notes = append(notes, line)
+			} else {
+				finish()
+			}
+			continue
+		}
+
+		finish()
+		release = &Release{Version: version[1], Date: headingDate.FindString(line)}
+		level = depth
+	}
+	finish()
+	return releases
+}
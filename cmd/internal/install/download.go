@@ -1,25 +1,130 @@
 package install
 
 import (
-	"io"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"io/ioutil"
-	"net/http"
 	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/web"
+)
+
+//lockRefresh is how often a running download touches its lock file. Locks which were not touched for
+//lockStale belong to a run which was killed and are taken over
+const (
+	lockRefresh = 10 * time.Second
+	lockStale   = 3 * lockRefresh
 )
 
-func download(url string) (*os.File, error) {
-	file, err := ioutil.TempFile("installing", "mod")
+//partial is the state of an unfinished download which is kept in the cache so a later run can resume it
+type partial struct {
+	URL string `json:"url"`
+	web.Validators
+}
+
+//download downloads the url into a new file in the work directory. The data is first written to a file named after
+//the url in the partial directory of the cache together with the validators of the server so an interrupted download
+//is continued by the next run. If another run downloads the same url at the moment a temporary file is used instead
+func download(ctx context.Context, cache, work, url string, progress Progress) (*os.File, error) {
+	report := func(current, total int64) {
+		progress.report(StageDownloading, current, total)
+	}
+
+	sum := sha256.Sum256([]byte(url))
+	path := filepath.Join(cache, "partial", hex.EncodeToString(sum[:16]))
+	unlock, locked := lock(path + ".lock")
+	if !locked {
+		file, err := ioutil.TempFile(work, "mod")
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return file, web.Download(ctx, url, file, report)
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	resp, err := http.Get(url)
+	var state partial
+	if data, err := ioutil.ReadFile(path + ".json"); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	if state.URL != url {
+		state = partial{URL: url}
+	}
+
+	err = web.Resume(ctx, url, file, state.Validators, func(validators web.Validators) error {
+		data, err := json.Marshal(partial{URL: url, Validators: validators})
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path+".json", data, 0644)
+	}, report)
 	if err != nil {
-		return file, err
+		return nil, err
 	}
-	defer resp.Body.Close()
+	file.Close()
+
+	//The finished download is moved into the work directory so the partial one does not get resumed again.
+	//The directory is created again since closed packages remove it once it is empty
+	if err := os.MkdirAll(work, os.ModePerm); err != nil {
+		return nil, err
+	}
+	target, err := ioutil.TempFile(work, "mod")
+	if err != nil {
+		return nil, err
+	}
+	target.Close()
+	if err := os.Rename(path, target.Name()); err != nil {
+		os.Remove(target.Name())
+		return nil, err
+	}
+	os.Remove(path + ".json")
+	return target, nil
+}
+
+//lock creates the lock file and keeps it fresh until unlock is called. It fails if another run holds the lock
+func lock(path string) (unlock func(), ok bool) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, false
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		if stat, statErr := os.Stat(path); statErr == nil && time.Since(stat.ModTime()) > lockStale {
+			os.Remove(path)
+			file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		}
+	}
+	if err != nil {
+		return nil, false
+	}
+	file.Close()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lockRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				os.Chtimes(path, now, now)
+			}
+		}
+	}()
 
-	_, err = io.Copy(file, resp.Body)
-	return file, err
+	return func() {
+		close(done)
+		os.Remove(path)
+	}, true
 }
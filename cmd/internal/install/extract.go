@@ -2,16 +2,17 @@ package install
 
 import (
 	"archive/zip"
-	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 )
 
-func extract(file *os.File) (string, error) {
-	dir, err := ioutil.TempDir("installing", "mod")
+func extract(work string, file *os.File, progress Progress) (string, error) {
+	dir, err := ioutil.TempDir(work, "mod")
 	if err != nil {
 		return "", err
 	}
@@ -22,13 +23,16 @@ func extract(file *os.File) (string, error) {
 	}
 	defer reader.Close()
 
-	for _, file := range reader.File {
+	total := int64(len(reader.File))
+	for i, file := range reader.File {
+		progress.report(StageExtracting, int64(i), total)
+
 		// Store filename/path for returning and using later on
 		fpath := filepath.Join(dir, file.Name)
 
 		// Check for ZipSlip. More Info: http://bit.ly/2MsjAWE
 		if !strings.HasPrefix(fpath, filepath.Clean(dir)+string(os.PathSeparator)) {
-			return dir, fmt.Errorf("%s: illegal file path", fpath)
+			return dir, errcode.New(errcode.InvalidMod, "%s: illegal file path", fpath)
 		}
 
 		if file.FileInfo().IsDir() {
@@ -62,5 +66,7 @@ func extract(file *os.File) (string, error) {
 			return dir, err
 		}
 	}
+
+	progress.report(StageExtracting, total, total)
 	return dir, nil
 }
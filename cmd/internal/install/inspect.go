@@ -0,0 +1,85 @@
+package install
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Inspect downloads the archive of a mod into the cache of the game and reads its manifest.
+//The archive is removed afterwards and nothing is extracted. The size of the archive is returned as well
+func Inspect(ctx context.Context, game, name string, progress Progress) (local.Mod, int64, error) {
+	mod, err := global.GetMod(name)
+	if err != nil {
+		return local.Mod{}, 0, err
+	}
+
+	path, err := DownloadArchive(ctx, game, mod, progress)
+	if err != nil {
+		return local.Mod{}, 0, err
+	}
+	defer os.Remove(path)
+
+	return InspectArchive(path, name)
+}
+
+//DownloadArchive downloads the archive of the mod into the cache of the game and verifies it without extracting it.
+//The returned file has to be removed by the caller
+func DownloadArchive(ctx context.Context, game string, mod global.Mod, progress Progress) (string, error) {
+	work, err := workDir(game)
+	if err != nil {
+		return "", err
+	}
+
+	//The work directory is inside the cache
+	file, err := download(ctx, filepath.Dir(work), work, mod.ArchiveLink, progress)
+	if err != nil {
+		if file != nil {
+			os.Remove(file.Name())
+		}
+		return "", err
+	}
+
+	if err := verify(file.Name(), mod.Hash.Sha256); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+//InspectArchive reads the manifest of the mod from its archive. The size of the archive is returned as well
+func InspectArchive(path, name string) (local.Mod, int64, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return local.Mod{}, 0, err
+	}
+	size := stat.Size()
+
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return local.Mod{}, size, errcode.New(errcode.InvalidMod, "cmd/internal: Could not read archive of mod '%s': %s", name, err.Error())
+	}
+	defer reader.Close()
+
+	pkg := local.FindManifest(reader.File)
+	if pkg == nil {
+		return local.Mod{}, size, errcode.New(errcode.InvalidMod, "cmd/internal: Could not find package of mod '%s'", name)
+	}
+
+	r, err := pkg.Open()
+	if err != nil {
+		return local.Mod{}, size, err
+	}
+	defer r.Close()
+
+	res, err := local.ReadManifest(pkg.Name, r)
+	if err != nil {
+		return local.Mod{}, size, errcode.New(errcode.InvalidMod, "cmd/internal: Could not parse package of mod '%s': %s", name, err.Error())
+	}
+	return res, size, nil
+}
@@ -1,68 +1,240 @@
 package install
 
 import (
-	"fmt"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
 )
 
-//Install a mod
-func Install(name string, override bool) error {
+//Package is a downloaded and extracted mod which is not installed yet
+type Package struct {
+	Name string
+
+	mod      global.Mod
+	game     string
+	work     string
+	file     string
+	dir      string
+	pkgDir   string
+	progress Progress
+}
+
+//Download a mod for the game and extract it into a temporary directory. The progress may be nil
+func Download(ctx context.Context, game, name string, progress Progress) (*Package, error) {
 	mod, err := global.GetMod(name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = os.MkdirAll("installing", os.ModePerm)
+	return DownloadMod(ctx, game, mod, progress)
+}
+
+//DownloadMod downloads the archive of the given mod instead of the one from the mod database
+func DownloadMod(ctx context.Context, game string, mod global.Mod, progress Progress) (*Package, error) {
+	work, err := workDir(game)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer os.RemoveAll("installing")
 
-	file, err := download(mod.ArchiveLink)
+	pkg := &Package{
+		Name:     mod.Name,
+		mod:      mod,
+		game:     game,
+		work:     work,
+		progress: progress,
+	}
+
+	//The work directory is inside the cache
+	file, err := download(ctx, filepath.Dir(work), work, mod.ArchiveLink, progress)
+	if file != nil {
+		pkg.file = file.Name()
+	}
 	if err != nil {
-		return err
+		pkg.Close()
+		return nil, err
 	}
-	defer os.Remove(file.Name())
 
-	dir, err := extract(file)
+	return pkg.unpack(ctx, file)
+}
+
+//Open reads the archive of the mod for the game from r instead of downloading it
+func Open(ctx context.Context, game string, mod global.Mod, r io.Reader, progress Progress) (*Package, error) {
+	work, err := workDir(game)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	pkg := &Package{
+		Name:     mod.Name,
+		mod:      mod,
+		game:     game,
+		work:     work,
+		progress: progress,
+	}
+
+	file, err := ioutil.TempFile(work, "mod")
+	if err != nil {
+		pkg.Close()
+		return nil, err
 	}
-	defer os.RemoveAll(dir)
+	pkg.file = file.Name()
 
-	pkgDir, found, err := findPackage(dir)
+	_, err = io.Copy(file, r)
+	file.Close()
+	if err != nil {
+		pkg.Close()
+		return nil, err
+	}
+
+	return pkg.unpack(ctx, file)
+}
+
+//WriteArchive copies the downloaded archive of the package to w
+func (pkg *Package) WriteArchive(w io.Writer) error {
+	file, err := os.Open(pkg.file)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+//unpack verifies and extracts the archive of the package
+func (pkg *Package) unpack(ctx context.Context, file *os.File) (*Package, error) {
+	if err := ctx.Err(); err != nil {
+		pkg.Close()
+		return nil, err
+	}
+
+	if err := verify(pkg.file, pkg.mod.Hash.Sha256); err != nil {
+		pkg.Close()
+		return nil, err
+	}
+
+	var err error
+	pkg.dir, err = extract(pkg.work, file, pkg.progress)
+	if err != nil {
+		pkg.Close()
+		return nil, err
+	}
+
+	var found bool
+	pkg.pkgDir, found, err = findPackage(pkg.dir)
+	if err != nil {
+		pkg.Close()
+		return nil, err
+	}
 	if !found {
-		return fmt.Errorf("cmd/internal: Could not find package of mod '%s'", name)
+		pkg.Close()
+		return nil, errcode.New(errcode.InvalidMod, "cmd/internal: Could not find package of mod '%s'", pkg.Name)
 	}
 
-	modDir, err := getModFolderName(name, override)
+	return pkg, nil
+}
+
+//Apply copies the package into the mods folder of the game
+func (pkg *Package) Apply(override bool) error {
+	modDir, err := getModFolderName(pkg.game, pkg.Name, override)
 	if err != nil {
 		return err
 	}
 
-	if mod.Dir != nil && mod.Dir.Any == "root" {
+	pkgDir := pkg.pkgDir
+	if pkg.mod.Dir != nil && pkg.mod.Dir.Any == "root" {
 		modDir = getRootDir(modDir)
 		pkgDir = getRootDir(pkgDir)
-		if !strings.HasPrefix(pkgDir, dir) {
-			return fmt.Errorf("cmd/internal: Mod '%s' does not have enough directories to be installed in root", name)
+		if !strings.HasPrefix(pkgDir, pkg.dir) {
+			return errcode.New(errcode.InvalidMod, "cmd/internal: Mod '%s' does not have enough directories to be installed in root", pkg.Name)
 		}
 	}
 
-	err = copyDir(modDir, pkgDir)
+	//Updates of packed mods are installed as folders next to the packed file which is removed afterwards
+	var packed string
+	if mod, err := local.GetMod(pkg.game, pkg.Name); override && err == nil && mod.Packaging == local.PackagingCCMod {
+		packed = mod.BasePath
+	}
+
+	pkg.progress.report(StageCopying, 0, -1)
+	defer local.Invalidate()
+	if err := copyDir(modDir, pkgDir); err != nil {
+		return err
+	}
+
+	if packed != "" {
+		return os.Remove(packed)
+	}
+	return nil
+}
+
+//Close removes the temporary files of the package
+func (pkg *Package) Close() error {
+	if pkg.file != "" {
+		os.Remove(pkg.file)
+	}
+	if pkg.dir != "" {
+		os.RemoveAll(pkg.dir)
+	}
+
+	//Only succeeds once no other package uses the directory anymore
+	os.Remove(pkg.work)
+	return nil
+}
+
+//workDir creates the directory for the temporary files of packages in the cache configured for the game
+func workDir(game string) (string, error) {
+	cfg, err := config.Effective(game)
+	if err != nil {
+		return "", err
+	}
+
+	cache, err := cfg.CacheDir()
 	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(cache, "installing")
+	return dir, os.MkdirAll(dir, os.ModePerm)
+}
+
+//verify compares the sha256 hash of the file with the one from the mod database if it is known
+func verify(path, expected string) error {
+	if expected == "" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return checkHash(file, expected)
+}
+
+//checkHash compares the sha256 hash of the content with the expected one
+func checkHash(r io.Reader, expected string) error {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
 		return err
 	}
 
+	actual := hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		return errcode.New(errcode.HashMismatch, "cmd/internal: Downloaded archive has the hash %s but %s was expected", actual, expected)
+	}
 	return nil
 }
 
@@ -73,7 +245,7 @@ func findPackage(dir string) (string, bool, error) {
 	}
 
 	for _, file := range files {
-		if !file.IsDir() && file.Name() == "package.json" {
+		if !file.IsDir() && local.IsManifest(file.Name()) {
 			return dir, true, nil
 		}
 	}
@@ -94,18 +266,22 @@ func findPackage(dir string) (string, bool, error) {
 	return dir, false, nil
 }
 
-func getModFolderName(name string, override bool) (string, error) {
-	path, err := local.GetGame()
-	if err != nil {
-		return path, err
+func getModFolderName(game, name string, override bool) (string, error) {
+	if override {
+		//Updates replace the installed mod even if it is disabled
+		if mod, err := local.GetMod(game, name); err == nil && mod.Packaging == local.PackagingCCMod {
+			return strings.TrimSuffix(mod.BasePath, filepath.Ext(mod.BasePath)), nil
+		} else if err == nil {
+			return mod.BasePath, nil
+		}
 	}
 
-	path = filepath.Join(path, "assets", "mods", name)
+	path := filepath.Join(game, "assets", "mods", name)
 	if override {
 		return path, nil
 	}
 
-	_, err = os.Stat(path)
+	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
 		return path, nil
 	}
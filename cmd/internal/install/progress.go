@@ -0,0 +1,17 @@
+package install
+
+//Stages of an installation reported to a Progress
+const (
+	StageDownloading = "downloading"
+	StageExtracting  = "extracting"
+	StageCopying     = "copying"
+)
+
+//Progress is called whenever an installation makes progress. Total is -1 if unknown
+type Progress func(stage string, current, total int64)
+
+func (progress Progress) report(stage string, current, total int64) {
+	if progress != nil {
+		progress(stage, current, total)
+	}
+}
@@ -0,0 +1,209 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Actions describing how a mod changed
+const (
+	ActionInstalled = "installed"
+	ActionUpdated   = "updated"
+	ActionRemoved   = "removed"
+	ActionEnabled   = "enabled"
+	ActionDisabled  = "disabled"
+)
+
+//Entry is an operation which changed the mods of a game
+type Entry struct {
+	Time      time.Time    `json:"time"`
+	Game      string       `json:"game"`
+	Operation string       `json:"operation"`
+	Args      []string     `json:"args,omitempty"`
+	Changes   []Change     `json:"changes"`
+	Warnings  []string     `json:"warnings,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Code      errcode.Code `json:"code,omitempty"`
+}
+
+//Change is a mod changed by an operation
+type Change struct {
+	Mod    string `json:"mod"`
+	Action string `json:"action"`
+	//From is the version before the operation. It is empty if the mod was installed
+	From string `json:"from,omitempty"`
+	//To is the version after the operation. It is empty if the mod was removed
+	To string `json:"to,omitempty"`
+}
+
+//Filter selects entries of the journal
+type Filter struct {
+	//Since skips entries which are older
+	Since time.Time
+	//Mod only selects entries which changed the mod
+	Mod string
+	//Limit is the maximum amount of entries. 0 means no limit
+	Limit int
+}
+
+//Diff returns the changes between the installed mods before and after an operation
+func Diff(before, after []local.Mod) []Change {
+	old := map[string]local.Mod{}
+	for _, mod := range before {
+		old[mod.Name] = mod
+	}
+
+	changes := []Change{}
+	for _, mod := range after {
+		prev, ok := old[mod.Name]
+		delete(old, mod.Name)
+
+		switch {
+		case !ok:
+			changes = append(changes, Change{Mod: mod.Name, Action: ActionInstalled, To: mod.Version})
+		case prev.Version != mod.Version:
+			changes = append(changes, Change{Mod: mod.Name, Action: ActionUpdated, From: prev.Version, To: mod.Version})
+		case prev.Enabled != mod.Enabled && mod.Enabled:
+			changes = append(changes, Change{Mod: mod.Name, Action: ActionEnabled, From: prev.Version, To: mod.Version})
+		case prev.Enabled != mod.Enabled:
+			changes = append(changes, Change{Mod: mod.Name, Action: ActionDisabled, From: prev.Version, To: mod.Version})
+		}
+	}
+
+	for _, mod := range before {
+		if _, ok := old[mod.Name]; ok {
+			changes = append(changes, Change{Mod: mod.Name, Action: ActionRemoved, From: mod.Version})
+		}
+	}
+	return changes
+}
+
+//Append adds the entry to the journal of its game
+func Append(entry *Entry) error {
+	path, err := path(entry.Game)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+//Read returns the entries of the journal of the game starting with the newest one
+func Read(game string, filter Filter) ([]*Entry, error) {
+	path, err := path(game)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			//Skip lines which were only written partially
+			continue
+		}
+
+		if entry.Time.Before(filter.Since) || !entry.changed(filter.Mod) {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+//ParseSince reads a point in time which is either a duration before now, a date or a RFC 3339 timestamp
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errcode.New(errcode.InvalidRequest, "cmd/internal: '%s' is neither a duration nor a date", value)
+}
+
+//changed checks if the entry changed the mod. Every entry matches an empty name
+func (entry *Entry) changed(mod string) bool {
+	if mod == "" {
+		return true
+	}
+
+	for _, change := range entry.Changes {
+		if change.Mod == mod {
+			return true
+		}
+	}
+
+	for _, arg := range entry.Args {
+		if strings.EqualFold(arg, mod) {
+			return true
+		}
+	}
+	return false
+}
+
+func path(game string) (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	key, err := config.GameKey(game)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "journal", key+".jsonl"), nil
+}
@@ -0,0 +1,179 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+//Stores in which installations of the game are discovered
+const (
+	SourceSteam = "steam"
+	SourceGOG   = "gog"
+	SourceItch  = "itch"
+)
+
+//Installation is a game folder found by Discover
+type Installation struct {
+	Path   string
+	Source string
+}
+
+//Discover searches the default locations of Steam, GOG and itch for installations of the game
+func Discover() []Installation {
+	home, _ := os.UserHomeDir()
+
+	found := []Installation{}
+	seen := map[string]bool{}
+	add := func(source string, candidates ...string) {
+		for _, dir := range candidates {
+			for _, sub := range []string{"", "game"} {
+				path := filepath.Join(dir, sub)
+				if abs, err := filepath.Abs(path); err == nil {
+					path = abs
+				}
+				if !seen[path] && IsGame(path) {
+					seen[path] = true
+					found = append(found, Installation{Path: path, Source: source})
+				}
+			}
+		}
+	}
+
+	for _, library := range steamLibraries(home) {
+		add(SourceSteam, filepath.Join(library, "steamapps", "common", "CrossCode"))
+	}
+
+	if home != "" {
+		add(SourceGOG,
+			filepath.Join(home, "GOG Games", "CrossCode"),
+			filepath.Join(home, "Games", "CrossCode"),
+			filepath.Join(home, "Games", "gog", "crosscode"),
+		)
+		add(SourceItch, itchApps(home)...)
+	}
+	if runtime.GOOS == "windows" {
+		add(SourceGOG,
+			`C:\Program Files (x86)\GOG Galaxy\Games\CrossCode`,
+			`C:\GOG Games\CrossCode`,
+		)
+	}
+	return found
+}
+
+//steamRoots returns the default installation folders of Steam
+func steamRoots(home string) []string {
+	switch runtime.GOOS {
+	case "windows":
+		return []string{`C:\Program Files (x86)\Steam`, `C:\Program Files\Steam`}
+	case "darwin":
+		return []string{filepath.Join(home, "Library", "Application Support", "Steam")}
+	}
+
+	return []string{
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+	}
+}
+
+//steamLibraries returns the Steam installations and the library folders listed in their libraryfolders.vdf
+func steamLibraries(home string) []string {
+	var libraries []string
+	for _, root := range steamRoots(home) {
+		if exists, _ := exists(root); !exists {
+			continue
+		}
+		libraries = append(libraries, root)
+
+		data, err := ioutil.ReadFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"))
+		if err != nil {
+			continue
+		}
+		libraries = append(libraries, parseLibraryFolders(string(data))...)
+	}
+	return libraries
+}
+
+//parseLibraryFolders reads the library paths of a libraryfolders.vdf. Newer files contain a "path"
+//key for every library while older ones map numbers directly to paths
+func parseLibraryFolders(vdf string) []string {
+	var paths []string
+	var key *string
+	depth := 0
+
+	for _, token := range vdfTokens(vdf) {
+		switch {
+		case token == "{":
+			depth++
+			key = nil
+		case token == "}":
+			depth--
+			key = nil
+		case key == nil:
+			value := token
+			key = &value
+		default:
+			if *key == "path" {
+				paths = append(paths, token)
+			} else if _, err := strconv.Atoi(*key); err == nil && depth == 1 {
+				paths = append(paths, token)
+			}
+			key = nil
+		}
+	}
+	return paths
+}
+
+//vdfTokens splits a Valve KeyValues file into unquoted strings and braces
+func vdfTokens(vdf string) []string {
+	var tokens []string
+	for i := 0; i < len(vdf); i++ {
+		switch c := vdf[i]; {
+		case c == '{' || c == '}':
+			tokens = append(tokens, string(c))
+		case c == '"':
+			var token strings.Builder
+			for i++; i < len(vdf) && vdf[i] != '"'; i++ {
+				if vdf[i] == '\\' && i+1 < len(vdf) {
+					i++
+				}
+				token.WriteByte(vdf[i])
+			}
+			tokens = append(tokens, token.String())
+		case c == '/' && i+1 < len(vdf) && vdf[i+1] == '/':
+			for i < len(vdf) && vdf[i] != '\n' {
+				i++
+			}
+		}
+	}
+	return tokens
+}
+
+//itchApps returns the folders of games installed by the itch app
+func itchApps(home string) []string {
+	dirs := []string{
+		filepath.Join(home, ".config", "itch", "apps"),
+		filepath.Join(home, "Library", "Application Support", "itch", "apps"),
+	}
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		dirs = append(dirs, filepath.Join(appData, "itch", "apps"))
+	}
+
+	var apps []string
+	for _, dir := range dirs {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir() && strings.Contains(strings.ToLower(file.Name()), "crosscode") {
+				apps = append(apps, filepath.Join(dir, file.Name()))
+			}
+		}
+	}
+	return apps
+}
@@ -0,0 +1,55 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+)
+
+//SetEnabled moves the mod between the mod folder it is located in and the folder of disabled mods belonging to it
+func (mod *Mod) SetEnabled(game string, enabled bool) error {
+	if mod.Enabled == enabled {
+		return nil
+	}
+
+	roots, err := ModRoots(game)
+	if err != nil {
+		return err
+	}
+
+	from := filepath.Dir(mod.BasePath)
+	to := ""
+	for _, root := range roots {
+		if root.Enabled != mod.Enabled || root.Path != from {
+			continue
+		}
+		if enabled {
+			to = strings.TrimSuffix(root.Path, disabledDir(""))
+		} else {
+			to = disabledDir(root.Path)
+		}
+	}
+	if to == "" {
+		return errcode.New(errcode.InvalidMod, "cmd/internal: Mod '%s' is not located in a mod folder of the game", mod.Name)
+	}
+
+	target := filepath.Join(to, filepath.Base(mod.BasePath))
+	if exists, _ := exists(target); exists {
+		return errcode.New(errcode.AlreadyExists, "cmd/internal: Could not move mod '%s' because %s already exists", mod.Name, target)
+	}
+
+	if err := os.MkdirAll(to, os.ModePerm); err != nil {
+		return err
+	}
+	err = os.Rename(mod.BasePath, target)
+	Invalidate()
+	if err != nil {
+		return err
+	}
+
+	mod.BasePath = target
+	mod.Enabled = enabled
+	return nil
+}
@@ -2,13 +2,15 @@ package local
 
 import (
 	"flag"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 )
 
-//GetGame using the current working directory or flags
+//GetGame using the flags, the configured game or the current working directory
 func GetGame() (string, error) {
 	dir, err := getDir()
 	if err != nil {
@@ -17,15 +19,58 @@ func GetGame() (string, error) {
 	return searchForGame(dir)
 }
 
+//FindGame returns the game folder selected by a path or the id of a registered game.
+//An empty selection falls back to GetGame
+func FindGame(selection string) (string, error) {
+	if selection == "" {
+		return GetGame()
+	}
+
+	cfg, err := config.Effective("")
+	if err != nil {
+		return "", err
+	}
+	return searchForGame(cfg.ResolveGame(selection))
+}
+
+//getDir returns the folder in which the game is searched. The game flag and the
+//configured game may either be paths or ids of registered games
 func getDir() (string, error) {
+	cfg, err := config.Effective("")
+	if err != nil {
+		return "", err
+	}
+
 	game := flag.Lookup("game")
-	if game != nil {
-		return game.Value.String(), nil
+	if game != nil && game.Value.String() != "" {
+		return cfg.ResolveGame(game.Value.String()), nil
+	}
+
+	if cfg.DefaultGame != "" {
+		return cfg.ResolveGame(cfg.DefaultGame), nil
 	}
 
 	return os.Getwd()
 }
 
+//Settings returns the configuration which applies to the current game.
+//Only the settings of the user are used if there is no game
+func Settings() (*config.Config, error) {
+	game, _ := GetGame()
+	return config.Effective(game)
+}
+
+//IsGame checks if the directory contains the game
+func IsGame(dir string) bool {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil || !containsPackage(files) {
+		return false
+	}
+
+	exists, _ := exists(filepath.Join(dir, "./assets/node-webkit.html"))
+	return exists
+}
+
 func searchForGame(dir string) (string, error) {
 	files, err := ioutil.ReadDir(dir)
 	if err != nil {
@@ -40,12 +85,18 @@ func searchForGame(dir string) (string, error) {
 
 	parent := filepath.Dir(dir)
 	if parent == dir {
-		return "", fmt.Errorf("cmd/internal: Could not find game")
+		return "", errcode.New(errcode.GameNotFound, "cmd/internal: Could not find game")
 	}
 
 	return searchForGame(parent)
 }
 
+//HasCCLoader checks if CCLoader is installed in the game folder
+func HasCCLoader(game string) bool {
+	exists, _ := exists(filepath.Join(game, "ccloader"))
+	return exists
+}
+
 func containsPackage(files []os.FileInfo) bool {
 	for _, file := range files {
 		if !file.IsDir() && file.Name() == "package.json" {
@@ -0,0 +1,242 @@
+package local
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+)
+
+//index contains the mods found in the mod folders of a game so lookups by name do not read every manifest again.
+//The configuration of the game is kept with it so it is only resolved once per index. The index is checked
+//once when an operation starts and is used without checking the files again until it is invalidated
+type index struct {
+	game   string
+	cfg    *config.Config
+	roots  []Root
+	states []fileState
+	mods   []Mod
+	broken []BrokenMod
+	byName map[string]int
+}
+
+//fileState is the modification time and size of a configuration file, a mod folder, a mod or a manifest when it was indexed.
+//Adding, removing or renaming mods changes the mod folders and editing a manifest changes the manifest
+//so the index is read again even if the files were changed by another program
+type fileState struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+func newFileState(path string, info os.FileInfo) fileState {
+	return fileState{path: path, modTime: info.ModTime(), size: info.Size()}
+}
+
+//statFile returns the current state of the file. Missing files have an empty state
+func statFile(path string) fileState {
+	if info, err := os.Stat(path); err == nil {
+		return newFileState(path, info)
+	}
+	return fileState{path: path}
+}
+
+var (
+	indexMutex sync.Mutex
+	indexes    = map[string]*index{}
+)
+
+//Invalidate drops the indexes of the installed mods so the next lookup reads the mod folders and the configuration again.
+//It has to be called after the mod folders or the configuration were changed
+func Invalidate() {
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+	indexes = map[string]*index{}
+}
+
+//Refresh drops the index of the game if its files were changed since it was created.
+//It is called when an operation starts since other programs may have changed the mods in the meantime
+func Refresh(game string) {
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	if idx := indexes[game]; idx != nil && !idx.valid() {
+		delete(indexes, game)
+	}
+}
+
+//RefreshAll drops the indexes of all games whose files were changed since they were created
+func RefreshAll() {
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	for game, idx := range indexes {
+		if !idx.valid() {
+			delete(indexes, game)
+		}
+	}
+}
+
+//indexed returns the index of the game and creates it if there is none
+func indexed(game string) (*index, error) {
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	if idx := indexes[game]; idx != nil {
+		return idx, nil
+	}
+
+	cfg, err := config.Effective(game)
+	if err != nil {
+		return nil, err
+	}
+	roots := modRoots(game, cfg)
+
+	var disk *diskIndex
+	if cfg.UsesModIndex() {
+		disk = readDiskIndex(cfg, game)
+	}
+
+	//The folders are stated before reading them so changes while reading invalidate the index
+	idx := &index{game: game, cfg: cfg, roots: roots, byName: map[string]int{}}
+	if path, err := config.Path(); err == nil {
+		idx.states = append(idx.states, statFile(path))
+	}
+	idx.states = append(idx.states, statFile(config.GamePath(game)), statFile(loaderConfigFile(game)))
+	for _, root := range roots {
+		idx.states = append(idx.states, statFile(root.Path))
+	}
+
+	mods, broken, states, err := scanRoots(roots, disk)
+	if err != nil {
+		return nil, err
+	}
+	idx.mods, idx.broken = mods, broken
+	idx.states = append(idx.states, states...)
+	for i := len(idx.mods) - 1; i >= 0; i-- {
+		idx.byName[idx.mods[i].Name] = i
+	}
+
+	//Failing to write the index only means that the manifests are read again next time
+	disk.save()
+
+	indexes[game] = idx
+	return idx, nil
+}
+
+//valid checks if none of the configuration files, mod folders, mods and manifests changed since the index was created
+func (idx *index) valid() bool {
+	for _, state := range idx.states {
+		current := statFile(state.path)
+		if !current.modTime.Equal(state.modTime) || current.size != state.size {
+			return false
+		}
+	}
+	return true
+}
+
+//get returns a copy of the indexed mods so callers can not change the index
+func (idx *index) get() ([]Mod, []BrokenMod) {
+	mods := make([]Mod, len(idx.mods))
+	copy(mods, idx.mods)
+	broken := make([]BrokenMod, len(idx.broken))
+	copy(broken, idx.broken)
+	return mods, broken
+}
+
+func (idx *index) find(name string) (Mod, bool) {
+	i, ok := idx.byName[name]
+	if !ok {
+		return Mod{}, false
+	}
+	return idx.mods[i], true
+}
+
+//indexVersion changes whenever the format of the index in the cache changes
+const indexVersion = 1
+
+//diskIndex is the index of a game in the cache. It keeps the mods read from manifests together with
+//the modification time and size of the manifest so mods are only read again if their manifest changed
+type diskIndex struct {
+	path    string
+	Version int                    `json:"version"`
+	Entries map[string]*indexEntry `json:"entries"`
+
+	used    map[string]*indexEntry
+	changed bool
+}
+
+type indexEntry struct {
+	Manifest string    `json:"manifest"`
+	ModTime  time.Time `json:"modTime"`
+	Size     int64     `json:"size"`
+	Mod      Mod       `json:"mod"`
+}
+
+//readDiskIndex reads the index of the game from the cache. A missing or unreadable index results in an empty one
+func readDiskIndex(cfg *config.Config, game string) *diskIndex {
+	cache, err := cfg.CacheDir()
+	if err != nil {
+		return nil
+	}
+	key, err := config.GameKey(game)
+	if err != nil {
+		return nil
+	}
+
+	disk := &diskIndex{path: filepath.Join(cache, "index", key+".json")}
+	if data, err := ioutil.ReadFile(disk.path); err == nil {
+		if json.Unmarshal(data, disk) != nil || disk.Version != indexVersion {
+			disk.Entries = nil
+		}
+	}
+	disk.Version = indexVersion
+	if disk.Entries == nil {
+		disk.Entries = map[string]*indexEntry{}
+	}
+	disk.used = map[string]*indexEntry{}
+	return disk
+}
+
+//read returns the indexed mod at the path if its manifest did not change and reads it otherwise.
+//A nil index always reads the mod
+func (disk *diskIndex) read(path, manifest string, info os.FileInfo, read func() (Mod, error)) (Mod, error) {
+	if disk == nil {
+		return read()
+	}
+
+	entry := disk.Entries[path]
+	if entry == nil || entry.Manifest != manifest || !entry.ModTime.Equal(info.ModTime()) || entry.Size != info.Size() {
+		mod, err := read()
+		if err != nil {
+			return Mod{}, err
+		}
+
+		entry = &indexEntry{Manifest: manifest, ModTime: info.ModTime(), Size: info.Size(), Mod: mod}
+		disk.changed = true
+	}
+
+	disk.used[path] = entry
+	return entry.Mod, nil
+}
+
+//save writes the index if mods were read again or removed. Mods which were not found anymore are dropped
+func (disk *diskIndex) save() error {
+	if disk == nil || (!disk.changed && len(disk.used) == len(disk.Entries)) {
+		return nil
+	}
+	disk.Entries = disk.used
+
+	data, err := json.Marshal(disk)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(disk.path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(disk.path, data, 0644)
+}
@@ -0,0 +1,166 @@
+package local
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+)
+
+//Names of the manifest files of mods
+const (
+	ManifestCCMod   = "ccmod.json"
+	ManifestPackage = "package.json"
+)
+
+//Manifests are the names of the manifest files in the order they are preferred in
+var Manifests = []string{ManifestCCMod, ManifestPackage}
+
+//IsManifest checks if the file is the manifest of a mod
+func IsManifest(file string) bool {
+	name := path.Base(file)
+	return name == ManifestCCMod || name == ManifestPackage
+}
+
+//ReadManifest parses the manifest of a mod which is not installed. The name of the manifest file selects the format.
+//The BasePath of the result is empty
+func ReadManifest(name string, r io.Reader) (Mod, error) {
+	if path.Base(name) == ManifestCCMod {
+		return ReadCCMod(r)
+	}
+	return ReadMod(r)
+}
+
+//ReadMod parses the package.json of a mod which is not installed. The BasePath of the result is empty
+func ReadMod(r io.Reader) (Mod, error) {
+	var data struct {
+		Name              string             `json:"name"`
+		Version           *string            `json:"version"`
+		Dependencies      *map[string]string `json:"dependencies"`
+		CcmodDependencies *map[string]string `json:"ccmodDependencies"`
+		HumanName         string             `json:"ccmodHumanName"`
+		Description       string             `json:"description"`
+		Homepage          string             `json:"homepage"`
+	}
+	err := json.NewDecoder(r).Decode(&data)
+	if err != nil {
+		return Mod{}, err
+	}
+
+	var version string
+	if data.Version != nil {
+		version = *data.Version
+	} else {
+		version = "0.0.0"
+	}
+
+	var dependencies map[string]string
+	if data.CcmodDependencies != nil {
+		dependencies = *data.CcmodDependencies
+	} else if data.Dependencies != nil {
+		dependencies = *data.Dependencies
+	}
+
+	return Mod{
+		Name:         data.Name,
+		Version:      version,
+		Dependencies: dependencies,
+		Enabled:      true,
+		Title:        data.HumanName,
+		Description:  data.Description,
+		Repository:   data.Homepage,
+		Manifest:     ManifestPackage,
+	}, nil
+}
+
+//ReadCCMod parses the ccmod.json of a mod which is not installed. The id is used as the name of the mod.
+//The BasePath of the result is empty
+func ReadCCMod(r io.Reader) (Mod, error) {
+	var data struct {
+		ID           string            `json:"id"`
+		Version      *string           `json:"version"`
+		Title        localized         `json:"title"`
+		Description  localized         `json:"description"`
+		Dependencies map[string]string `json:"dependencies"`
+		Icons        map[string]string `json:"icons"`
+		Authors      authors           `json:"authors"`
+		Repository   string            `json:"repository"`
+	}
+	err := json.NewDecoder(r).Decode(&data)
+	if err != nil {
+		return Mod{}, err
+	}
+
+	version := "0.0.0"
+	if data.Version != nil {
+		version = *data.Version
+	}
+
+	return Mod{
+		Name:         data.ID,
+		Version:      version,
+		Dependencies: data.Dependencies,
+		Enabled:      true,
+		Title:        string(data.Title),
+		Description:  string(data.Description),
+		Icons:        data.Icons,
+		Authors:      data.Authors,
+		Repository:   data.Repository,
+		Manifest:     ManifestCCMod,
+	}, nil
+}
+
+//localized is a text which is either a plain string or an object mapping locales to strings.
+//The English text is used if there is one
+type localized string
+
+func (l *localized) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*l = localized(text)
+		return nil
+	}
+
+	var texts map[string]string
+	if err := json.Unmarshal(data, &texts); err != nil {
+		return err
+	}
+
+	if text, ok := texts["en_US"]; ok {
+		*l = localized(text)
+		return nil
+	}
+
+	locales := make([]string, 0, len(texts))
+	for locale := range texts {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	if len(locales) > 0 {
+		*l = localized(texts[locales[0]])
+	}
+	return nil
+}
+
+//authors is either a single author or a list of them
+type authors []string
+
+func (a *authors) UnmarshalJSON(data []byte) error {
+	//null would be decoded as an empty author
+	if string(data) == "null" {
+		return nil
+	}
+
+	var author string
+	if err := json.Unmarshal(data, &author); err == nil {
+		*a = authors{author}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
@@ -2,102 +2,271 @@ package local
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 )
 
 //Mod contains the data of the installed mod
 type Mod struct {
+	//Name is the id of the mod in ccmod.json manifests
 	Name         string
 	BasePath     string
 	Version      string
 	Dependencies map[string]string
+	Enabled      bool
+
+	//Title is the human readable name of the mod in English
+	Title       string
+	Description string
+	//Icons maps the size of an icon to its path inside the mod
+	Icons      map[string]string
+	Authors    []string
+	Repository string
+	//Manifest is the name of the file the mod was read from
+	Manifest string
+	//Packaging tells if the mod is a folder or a packed .ccmod file. BasePath is the location of either
+	Packaging string
+}
+
+//Packagings of installed mods
+const (
+	PackagingDirectory = "directory"
+	PackagingCCMod     = "ccmod"
+)
+
+//Root is a folder containing mods
+type Root struct {
+	Path    string `json:"path"`
+	Enabled bool   `json:"enabled"`
+}
+
+//GetMods finds all local mods of the game including disabled ones
+func GetMods(game string) ([]Mod, error) {
+	mods, _, err := ScanMods(game)
+	return mods, err
+}
+
+//BrokenMod is a folder in the mod folders whose manifest could not be read
+type BrokenMod struct {
+	Path    string
+	Enabled bool
+	Err     error
+}
+
+//GetModsIn finds all mods in the standard mod folders of the given folder and the extra folders including disabled ones.
+//The configured mod folders of the game are ignored so it can be used for copies of the game like snapshots
+func GetModsIn(game string, extra ...Root) ([]Mod, error) {
+	mods, _, _, err := scanRoots(append(standardRoots(game), extra...), nil)
+	return mods, err
+}
+
+//ScanMods finds all mods of the given game folder including disabled ones
+//and the folders which are skipped since they do not contain a valid mod
+func ScanMods(game string) ([]Mod, []BrokenMod, error) {
+	idx, err := indexed(game)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mods, broken := idx.get()
+	return mods, broken, nil
 }
 
-//GetMods finds all local mods
-func GetMods() ([]Mod, error) {
-	game, err := GetGame()
+//ModRoots returns the folders containing mods of the game: assets/mods, the folder of disabled mods
+//and the additional folders the mod loader is configured to load mods from. Every additional folder
+//has its own folder of disabled mods next to it so disabled mods are enabled into the folder they came from
+func ModRoots(game string) ([]Root, error) {
+	idx, err := indexed(game)
 	if err != nil {
 		return nil, err
 	}
 
-	mods := filepath.Join(game, "assets/mods")
+	roots := make([]Root, len(idx.roots))
+	copy(roots, idx.roots)
+	return roots, nil
+}
+
+func modRoots(game string, cfg *config.Config) []Root {
+	roots := standardRoots(game)
+	for _, dir := range extraModDirs(game, cfg) {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(game, dir)
+		}
+		dir = filepath.Clean(dir)
+		if dir == roots[0].Path {
+			continue
+		}
+		roots = append(roots, Root{Path: dir, Enabled: true}, Root{Path: disabledDir(dir), Enabled: false})
+	}
+	return roots
+}
+
+//loaderConfig is the part of the configuration of CCLoader which lists the folders it loads mods from
+type loaderConfig struct {
+	ModsDirs []string `json:"modsDirs"`
+}
+
+//extraModDirs returns the mod folders from the configuration of the mod loader in ccloader/config.json.
+//The modDirs setting is only used if the loader does not list any folders
+func extraModDirs(game string, cfg *config.Config) []string {
+	data, err := ioutil.ReadFile(loaderConfigFile(game))
+	if err == nil {
+		var loader loaderConfig
+		if json.Unmarshal(data, &loader) == nil && len(loader.ModsDirs) > 0 {
+			return loader.ModsDirs
+		}
+	}
+	return cfg.ModDirs
+}
+
+func loaderConfigFile(game string) string {
+	return filepath.Join(game, "ccloader", "config.json")
+}
+
+func standardRoots(game string) []Root {
+	return []Root{
+		{Path: filepath.Join(game, "assets", "mods"), Enabled: true},
+		{Path: DisabledModsDir(game), Enabled: false},
+	}
+}
+
+//scanRoots reads the mods in the folders. The state of every mod folder and manifest is returned
+//as well so changes to them can be detected
+func scanRoots(roots []Root, disk *diskIndex) ([]Mod, []BrokenMod, []fileState, error) {
+	result := []Mod{}
+	var broken []BrokenMod
+	var states []fileState
+	seen := map[string]bool{}
+	for _, root := range roots {
+		if seen[root.Path] {
+			continue
+		}
+		seen[root.Path] = true
+
+		mods, skipped, found, err := findMods(root.Path, disk)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		for _, mod := range mods {
+			mod.Enabled = root.Enabled
+			result = append(result, mod)
+		}
+		for _, mod := range skipped {
+			mod.Enabled = root.Enabled
+			broken = append(broken, mod)
+		}
+		states = append(states, found...)
+	}
+
+	return result, broken, states, nil
+}
+
+//DisabledModsDir returns the folder containing the disabled mods of the game.
+//CCLoader only loads mods from assets/mods so mods in this folder are ignored
+func DisabledModsDir(game string) string {
+	return disabledDir(filepath.Join(game, "assets", "mods"))
+}
+
+//disabledDir returns the folder containing the disabled mods of a mod folder
+func disabledDir(root string) string {
+	return root + "-disabled"
+}
+
+func findMods(mods string, disk *diskIndex) ([]Mod, []BrokenMod, []fileState, error) {
 	if exists, _ := exists(mods); !exists {
-		return []Mod{}, nil
+		return nil, nil, nil, nil
 	}
 
 	dirs, err := ioutil.ReadDir(mods)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	var result []Mod
+	var broken []BrokenMod
+	var states []fileState
 	for _, dir := range dirs {
-		if dir.IsDir() {
-			mod, err := parseMod(filepath.Join(mods, dir.Name(), "package.json"))
+		path := filepath.Join(mods, dir.Name())
+
+		var mod Mod
+		switch {
+		case dir.IsDir():
+			//The folder changes if its manifest is added, removed or replaced and the manifest if it is edited
+			states = append(states, newFileState(path, dir))
+			var manifest string
+			var info os.FileInfo
+			manifest, info, err = findManifestFile(path)
 			if err == nil {
-				result = append(result, mod)
+				states = append(states, newFileState(manifest, info))
+				mod, err = disk.read(path, manifest, info, func() (Mod, error) { return parseMod(path, manifest) })
 			}
+		case IsPacked(dir.Name()):
+			states = append(states, newFileState(path, dir))
+			mod, err = disk.read(path, path, dir, func() (Mod, error) { return readPacked(path) })
+		default:
+			continue
+		}
+
+		if err != nil {
+			broken = append(broken, BrokenMod{Path: path, Err: err})
+			continue
 		}
+		result = append(result, mod)
 	}
 
-	return result, nil
+	return result, broken, states, nil
 }
 
-//GetMod finds the installed mod by name
-func GetMod(name string) (Mod, error) {
-	mods, err := GetMods()
+//GetMod finds the installed mod of the game by name
+func GetMod(game, name string) (Mod, error) {
+	idx, err := indexed(game)
 	if err != nil {
 		return Mod{}, err
 	}
 
-	for _, mod := range mods {
-		if name == mod.Name {
-			return mod, nil
-		}
+	if mod, ok := idx.find(name); ok {
+		return mod, nil
 	}
 
-	return Mod{}, fmt.Errorf("cmd/internal: Could not find mod '%s'", name)
+	return Mod{}, errcode.New(errcode.NotInstalled, "cmd/internal: Could not find mod '%s'", name)
 }
 
-func parseMod(path string) (Mod, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return Mod{}, nil
+//findManifestFile returns the manifest of the mod in the folder. ccmod.json is preferred over package.json
+func findManifestFile(dir string) (string, os.FileInfo, error) {
+	var err error
+	for _, manifest := range Manifests {
+		var info os.FileInfo
+		path := filepath.Join(dir, manifest)
+		info, err = os.Stat(path)
+		if err == nil {
+			return path, info, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", nil, err
+		}
 	}
-	defer file.Close()
+	return "", nil, err
+}
 
-	var data struct {
-		Name              string             `json:"name"`
-		Version           *string            `json:"version"`
-		Dependencies      *map[string]string `json:"dependencies"`
-		CcmodDependencies *map[string]string `json:"ccmodDependencies"`
-	}
-	err = json.NewDecoder(file).Decode(&data)
+//parseMod reads the mod in the folder from its manifest
+func parseMod(dir, manifest string) (Mod, error) {
+	file, err := os.Open(manifest)
 	if err != nil {
-		return Mod{}, nil
-	}
-
-	var version string
-	if data.Version != nil {
-		version = *data.Version
-	} else {
-		version = "0.0.0"
+		return Mod{}, err
 	}
+	defer file.Close()
 
-	var dependencies map[string]string
-	if data.CcmodDependencies != nil {
-		dependencies = *data.CcmodDependencies
-	} else if data.Dependencies != nil {
-		dependencies = *data.Dependencies
+	mod, err := ReadManifest(filepath.Base(manifest), file)
+	if err != nil {
+		return Mod{}, err
 	}
 
-	return Mod{
-		data.Name,
-		filepath.Dir(path),
-		version,
-		dependencies,
-	}, nil
+	mod.BasePath = dir
+	mod.Packaging = PackagingDirectory
+	return mod, nil
 }
@@ -0,0 +1,116 @@
+package local
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//PackedExtension is the extension of mods packed into a single zip file
+const PackedExtension = ".ccmod"
+
+//IsPacked checks if the file is a packed mod
+func IsPacked(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), PackedExtension)
+}
+
+//FindManifest returns the manifest of the mod in an archive or nil if there is none.
+//The manifest closest to the root belongs to the mod and a ccmod.json is preferred over a package.json in the same folder
+func FindManifest(files []*zip.File) *zip.File {
+	var manifest *zip.File
+	for _, file := range files {
+		if !IsManifest(file.Name) || file.FileInfo().IsDir() {
+			continue
+		}
+
+		depth := strings.Count(file.Name, "/")
+		if manifest == nil || depth < strings.Count(manifest.Name, "/") ||
+			(path.Dir(file.Name) == path.Dir(manifest.Name) && path.Base(file.Name) == ManifestCCMod) {
+			manifest = file
+		}
+	}
+	return manifest
+}
+
+//readPacked reads the manifest of a packed mod without extracting it
+func readPacked(file string) (Mod, error) {
+	reader, err := zip.OpenReader(file)
+	if err != nil {
+		return Mod{}, err
+	}
+	defer reader.Close()
+
+	manifest := FindManifest(reader.File)
+	if manifest == nil {
+		return Mod{}, os.ErrNotExist
+	}
+
+	r, err := manifest.Open()
+	if err != nil {
+		return Mod{}, err
+	}
+	defer r.Close()
+
+	mod, err := ReadManifest(manifest.Name, r)
+	if err != nil {
+		return Mod{}, err
+	}
+
+	mod.BasePath = file
+	mod.Packaging = PackagingCCMod
+	return mod, nil
+}
+
+//WriteArchive writes the installed mod to w as a zip file which can be installed again. Packed mods are copied as they are
+func (mod Mod) WriteArchive(w io.Writer) error {
+	if mod.Packaging == PackagingCCMod {
+		return copyTo(w, mod.BasePath)
+	}
+
+	//Mods linked into the mods folder are stored with the files of their target
+	root, err := filepath.EvalSymlinks(mod.BasePath)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	err = filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+
+		header := &zip.FileHeader{
+			Name:     filepath.ToSlash(rel),
+			Method:   zip.Deflate,
+			Modified: info.ModTime(),
+		}
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		return copyTo(entry, file)
+	})
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func copyTo(w io.Writer, file string) error {
+	r, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	_, err = io.Copy(w, r)
+	return err
+}
@@ -0,0 +1,181 @@
+package modpack
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+)
+
+//Format is the version of the modpack file format
+const Format = 1
+
+//packFile is the name of the modpack inside of a bundle
+const packFile = "pack.json"
+
+//Pack is an exact set of mods which can be installed into another game
+type Pack struct {
+	Format int               `json:"format"`
+	Tools  map[string]string `json:"tools,omitempty"`
+	Mods   []Mod             `json:"mods"`
+}
+
+//Mod is a mod of a modpack
+type Mod struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Source  string `json:"source,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+	Dir     string `json:"dir,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+//Global returns the mod in the format of the mod database so it can be installed
+func (m Mod) Global() global.Mod {
+	mod := global.Mod{
+		Name:        m.Name,
+		Version:     m.Version,
+		ArchiveLink: m.Source,
+	}
+	mod.Hash.Sha256 = m.Hash
+	if m.Dir != "" {
+		mod.Dir = &struct {
+			Any string `json:"any"`
+		}{m.Dir}
+	}
+	return mod
+}
+
+//Write encodes the modpack as JSON
+func (p *Pack) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(p)
+}
+
+//Read decodes a modpack from JSON
+func Read(r io.Reader) (*Pack, error) {
+	var p Pack
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, errcode.New(errcode.InvalidRequest, "cmd/internal: Could not parse modpack: %s", err.Error())
+	}
+
+	if p.Format != Format {
+		return nil, errcode.New(errcode.InvalidRequest, "cmd/internal: Unsupported modpack format %d", p.Format)
+	}
+	return &p, nil
+}
+
+//Bundle is a zip file containing a modpack and the archives of its mods
+type Bundle struct {
+	Pack *Pack
+
+	reader *zip.ReadCloser
+}
+
+//Open reads a modpack file. If the file is a bundle it is returned as well and has to be closed
+func Open(file string) (*Pack, *Bundle, error) {
+	if reader, err := zip.OpenReader(file); err == nil {
+		bundle := &Bundle{reader: reader}
+		bundle.Pack, err = bundle.readPack()
+		if err != nil {
+			reader.Close()
+			return nil, nil, err
+		}
+		return bundle.Pack, bundle, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	p, err := Read(f)
+	return p, nil, err
+}
+
+func (b *Bundle) readPack() (*Pack, error) {
+	for _, file := range b.reader.File {
+		if file.Name == packFile {
+			r, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return Read(r)
+		}
+	}
+	return nil, errcode.New(errcode.InvalidRequest, "cmd/internal: Bundle does not contain %s", packFile)
+}
+
+//Contains checks if the bundle stores the archive of the mod
+func (b *Bundle) Contains(name string) bool {
+	return b.find(name) != nil
+}
+
+//Archive opens the archive of the mod stored in the bundle. The reader has to be closed
+func (b *Bundle) Archive(name string) (io.ReadCloser, bool, error) {
+	file := b.find(name)
+	if file == nil {
+		return nil, false, nil
+	}
+	r, err := file.Open()
+	return r, true, err
+}
+
+func (b *Bundle) find(name string) *zip.File {
+	for _, file := range b.reader.File {
+		if file.Name == archiveName(name) {
+			return file
+		}
+	}
+	return nil
+}
+
+//Close closes the bundle file
+func (b *Bundle) Close() error {
+	return b.reader.Close()
+}
+
+//BundleWriter creates a bundle. The archives have to be added before the modpack is written
+type BundleWriter struct {
+	writer *zip.Writer
+}
+
+//NewBundleWriter creates a bundle which is written to w
+func NewBundleWriter(w io.Writer) *BundleWriter {
+	return &BundleWriter{zip.NewWriter(w)}
+}
+
+//Archive returns a writer for the archive of the mod
+func (b *BundleWriter) Archive(name string) (io.Writer, error) {
+	//Archives are zip files already so compressing them again does not help
+	return b.writer.CreateHeader(&zip.FileHeader{
+		Name:     archiveName(name),
+		Method:   zip.Store,
+		Modified: time.Now(),
+	})
+}
+
+//Close writes the modpack and finishes the bundle
+func (b *BundleWriter) Close(p *Pack) error {
+	w, err := b.writer.Create(packFile)
+	if err != nil {
+		return err
+	}
+
+	if err := p.Write(w); err != nil {
+		return err
+	}
+	return b.writer.Close()
+}
+
+func archiveName(name string) string {
+	return path.Join("mods", name+".zip")
+}
@@ -0,0 +1,152 @@
+package profile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+)
+
+//Profile is a named set of mods
+type Profile struct {
+	Name string `json:"name"`
+	Mods []Mod  `json:"mods"`
+}
+
+//Mod is a mod of a profile
+type Mod struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Enabled bool   `json:"enabled"`
+}
+
+//Find returns the mod of the profile with the name
+func (p *Profile) Find(name string) (Mod, bool) {
+	for _, mod := range p.Mods {
+		if mod.Name == name {
+			return mod, true
+		}
+	}
+	return Mod{}, false
+}
+
+//Load reads the profile with the name
+func Load(name string) (*Profile, error) {
+	path, err := path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, errcode.New(errcode.NotFound, "cmd/internal: Could not find profile '%s'", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+//Save writes the profile replacing a profile with the same name
+func (p *Profile) Save() error {
+	path, err := path(p.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+//Exists checks if there is a profile with the name
+func Exists(name string) bool {
+	path, err := path(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+//Delete removes the profile with the name
+func Delete(name string) error {
+	path, err := path(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return errcode.New(errcode.NotFound, "cmd/internal: Could not find profile '%s'", name)
+	}
+	return err
+}
+
+//List returns all profiles sorted by name
+func List() ([]*Profile, error) {
+	dir, err := dir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []*Profile
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		p, err := Load(strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+func dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profiles"), nil
+}
+
+func path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\:`) || strings.HasPrefix(name, ".") {
+		return "", errcode.New(errcode.InvalidRequest, "cmd/internal: Invalid profile name '%s'", name)
+	}
+
+	dir, err := dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
@@ -0,0 +1,389 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//DefaultLimit is the amount of snapshots kept per game if none is configured
+const DefaultLimit = 10
+
+//DefaultMaxAge is how long snapshots are kept if nothing else is configured
+const DefaultMaxAge = 30 * 24 * time.Hour
+
+//paths are the parts of the game which are saved relative to the game folder.
+//Besides the mods these are the files tools like CCLoader change. Additional mod folders are saved as well
+var paths = []string{
+	filepath.Join("assets", "mods"),
+	filepath.Join("assets", "mods-disabled"),
+	"package.json",
+	filepath.Join("assets", "node-webkit.html"),
+}
+
+//Snapshot is a copy of the mods of a game taken before an operation
+type Snapshot struct {
+	ID        int       `json:"id"`
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Args      []string  `json:"args,omitempty"`
+	Game      string    `json:"game"`
+	//Roots are the additional mod folders of the game which are saved in the folder roots of the snapshot by their index
+	Roots []local.Root `json:"roots,omitempty"`
+}
+
+//Take saves the current state of the game and removes snapshots that exceed the retention limits
+func Take(game, operation string, args []string) (*Snapshot, error) {
+	dir, err := gameDir(game)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := List(game)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		ID:        1,
+		Time:      time.Now(),
+		Operation: operation,
+		Args:      args,
+		Game:      game,
+	}
+	if len(snapshots) > 0 {
+		snap.ID = snapshots[0].ID + 1
+	}
+
+	roots, err := local.ModRoots(game)
+	if err != nil {
+		return nil, err
+	}
+	snap.Roots = extraRoots(game, roots)
+
+	snapDir := filepath.Join(dir, strconv.Itoa(snap.ID))
+	for _, path := range paths {
+		if err := copyPath(filepath.Join(snapDir, "files", path), resolve(filepath.Join(game, path))); err != nil {
+			os.RemoveAll(snapDir)
+			return nil, err
+		}
+	}
+	for i, root := range snap.Roots {
+		if err := copyPath(filepath.Join(snapDir, "roots", strconv.Itoa(i)), resolve(root.Path)); err != nil {
+			os.RemoveAll(snapDir)
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(snap, "", "\t")
+	if err != nil {
+		os.RemoveAll(snapDir)
+		return nil, err
+	}
+
+	//The metadata is written last so incomplete snapshots are never listed
+	if err := ioutil.WriteFile(filepath.Join(snapDir, "snapshot.json"), data, 0644); err != nil {
+		os.RemoveAll(snapDir)
+		return nil, err
+	}
+
+	//Snapshots which could not be removed now are removed the next time
+	prune(game, append([]*Snapshot{snap}, snapshots...))
+	return snap, nil
+}
+
+//List returns the snapshots of the game starting with the newest one
+func List(game string) ([]*Snapshot, error) {
+	dir, err := gameDir(game)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []*Snapshot
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, file.Name(), "snapshot.json"))
+		if err != nil {
+			continue
+		}
+
+		var snap Snapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, &snap)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID > snapshots[j].ID })
+	return snapshots, nil
+}
+
+//Find returns the snapshot of the game with the id
+func Find(game string, id int) (*Snapshot, error) {
+	snapshots, err := List(game)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snap := range snapshots {
+		if snap.ID == id {
+			return snap, nil
+		}
+	}
+	return nil, errcode.New(errcode.NotFound, "cmd/internal: Could not find snapshot %d", id)
+}
+
+//Restore replaces the mods and files of the game with the ones from the snapshot
+func (snap *Snapshot) Restore() error {
+	dir, err := gameDir(snap.Game)
+	if err != nil {
+		return err
+	}
+
+	snapDir := filepath.Join(dir, strconv.Itoa(snap.ID))
+	defer local.Invalidate()
+	for _, path := range paths {
+		if err := restorePath(resolve(filepath.Join(snap.Game, path)), filepath.Join(snapDir, "files", path)); err != nil {
+			return err
+		}
+	}
+	//Mod folders which were added to the configuration after the snapshot was taken are left alone
+	for i, root := range snap.Roots {
+		if err := restorePath(resolve(root.Path), filepath.Join(snapDir, "roots", strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//restorePath replaces dst with the saved copy in src. A missing folder means there were no mods
+//but game files which are missing are left alone
+func restorePath(dst, src string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		if stat, err := os.Stat(dst); err != nil || !stat.IsDir() {
+			return nil
+		}
+	}
+
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return copyPath(dst, src)
+}
+
+//extraRoots returns the mod folders of the game besides the ones in paths
+func extraRoots(game string, roots []local.Root) []local.Root {
+	var extra []local.Root
+	for _, root := range roots {
+		standard := false
+		for _, path := range paths {
+			if root.Path == filepath.Join(game, path) {
+				standard = true
+			}
+		}
+		if !standard {
+			extra = append(extra, root)
+		}
+	}
+	return extra
+}
+
+//Mods returns the mods saved in the snapshot
+func (snap *Snapshot) Mods() ([]local.Mod, error) {
+	dir, err := gameDir(snap.Game)
+	if err != nil {
+		return nil, err
+	}
+	snapDir := filepath.Join(dir, strconv.Itoa(snap.ID))
+	var roots []local.Root
+	for i, root := range snap.Roots {
+		roots = append(roots, local.Root{Path: filepath.Join(snapDir, "roots", strconv.Itoa(i)), Enabled: root.Enabled})
+	}
+	return local.GetModsIn(filepath.Join(snapDir, "files"), roots...)
+}
+
+//prune removes old snapshots. The newest snapshot is always kept
+func prune(game string, snapshots []*Snapshot) error {
+	limit, maxAge := limits(game)
+
+	dir, err := gameDir(game)
+	if err != nil {
+		return err
+	}
+
+	for i, snap := range snapshots {
+		if i == 0 || (i < limit && time.Since(snap.Time) <= maxAge) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(dir, strconv.Itoa(snap.ID))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//limits returns the retention limits from the configuration
+func limits(game string) (int, time.Duration) {
+	limit, maxAge := DefaultLimit, DefaultMaxAge
+
+	cfg, err := config.Effective(game)
+	if err != nil {
+		return limit, maxAge
+	}
+
+	if cfg.SnapshotLimit > 0 {
+		limit = cfg.SnapshotLimit
+	}
+	if cfg.SnapshotDays > 0 {
+		maxAge = time.Duration(cfg.SnapshotDays) * 24 * time.Hour
+	}
+	return limit, maxAge
+}
+
+//gameDir returns the folder containing the snapshots of the game. Snapshots are copies of the mods
+//so they are kept in the cache instead of the configuration folder which is often synced
+func gameDir(game string) (string, error) {
+	cfg, err := config.Effective(game)
+	if err != nil {
+		return "", err
+	}
+	cache, err := cfg.CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	key, err := config.GameKey(game)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(cache, "snapshots", key)
+	moveLegacy(dir, key)
+	return dir, nil
+}
+
+//moveLegacy moves the snapshots which older versions kept in the configuration folder to dir
+func moveLegacy(dir, key string) {
+	cfgDir, err := config.Dir()
+	if err != nil {
+		return
+	}
+
+	legacy := filepath.Join(cfgDir, "snapshots", key)
+	if _, err := os.Stat(legacy); err != nil {
+		return
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return
+	}
+	if os.Rename(legacy, dir) == nil {
+		return
+	}
+
+	//The cache may be on another drive
+	if err := copyPath(dir, legacy); err != nil {
+		os.RemoveAll(dir)
+		return
+	}
+	os.RemoveAll(legacy)
+}
+
+//resolve follows the links of a saved folder so the mods of linked mod folders are saved instead of the link
+func resolve(path string) string {
+	if res, err := filepath.EvalSymlinks(path); err == nil {
+		return res
+	}
+	return path
+}
+
+//copyPath copies a file or folder. Links are copied as links so linked mods are neither copied nor followed
+//into loops. Missing sources are ignored
+func copyPath(dst, src string) error {
+	stat, err := os.Lstat(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if stat.Mode()&os.ModeSymlink != 0 {
+		return copyLink(dst, src)
+	}
+	if !stat.IsDir() {
+		return copyFile(dst, src, stat.Mode())
+	}
+
+	if err := os.MkdirAll(dst, stat.Mode()); err != nil {
+		return err
+	}
+
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := copyPath(filepath.Join(dst, file.Name()), filepath.Join(src, file.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyLink(dst, src string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	return os.Symlink(target, dst)
+}
+
+func copyFile(dst, src string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	_, err = io.Copy(dstFile, srcFile)
+	return err
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+)
+
+const (
+	connectTimeout = 30 * time.Second
+	readTimeout    = 30 * time.Second
+	maxAttempts    = 5
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 15 * time.Second
+)
+
+//Client is shared by every request of the tool
+var Client = &http.Client{
+	Transport: &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: readTimeout,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          16,
+	},
+}
+
+//proxy uses the proxy flag if it is set, then the proxy setting and the environment otherwise
+func proxy(req *http.Request) (*url.URL, error) {
+	value := ""
+	if f := flag.Lookup("proxy"); f != nil {
+		value = f.Value.String()
+	}
+	if value == "" {
+		if cfg, err := config.Effective(""); err == nil {
+			value = cfg.Proxy
+		}
+	}
+	if value == "" {
+		return http.ProxyFromEnvironment(req)
+	}
+
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("cmd/internal: Invalid proxy '%s': %s", value, err.Error())
+	}
+	return proxyURL, nil
+}
+
+//Get requests the url and retries transient errors with an exponential backoff
+func Get(ctx context.Context, url string) (*http.Response, error) {
+	var resp *http.Response
+	err := retry(ctx, func() (bool, error) {
+		var err error
+		resp, err = do(ctx, url, nil)
+		if err != nil {
+			return true, err
+		}
+
+		if retryable(resp.StatusCode) {
+			resp.Body.Close()
+			return true, errcode.New(errcode.DownloadFailed, "cmd/internal: Could not get '%s': %s", url, resp.Status)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return false, errcode.New(errcode.DownloadFailed, "cmd/internal: Could not get '%s': %s", url, resp.Status)
+		}
+		return false, nil
+	})
+	return resp, err
+}
+
+func do(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	return Client.Do(req.WithContext(ctx))
+}
+
+//retry calls the attempt until it succeeds, fails permanently or the attempts are exhausted
+func retry(ctx context.Context, attempt func() (bool, error)) error {
+	backoff := initialBackoff
+	for i := 1; ; i++ {
+		again, err := attempt()
+		if err == nil || !again || i == maxAttempts {
+			return downloadError(ctx, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func retryable(status int) bool {
+	return status >= 500 || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+}
+
+//downloadError gives errors without a code the DownloadFailed code
+func downloadError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errcode.Of(err) != errcode.Unknown {
+		return err
+	}
+	return errcode.New(errcode.DownloadFailed, "cmd/internal: Download failed: %s", err.Error())
+}
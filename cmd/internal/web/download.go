@@ -0,0 +1,179 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+)
+
+//Download writes the content of the url into the file. Interrupted downloads
+//are resumed using range requests if the server supports them.
+//The progress may be nil and receives -1 as total if the size is unknown
+func Download(ctx context.Context, url string, file *os.File, progress func(current, total int64)) error {
+	return Resume(ctx, url, file, Validators{}, nil, progress)
+}
+
+//Validators identify the version of a file on the server so a partial download is only continued if the file did not change
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+func (v Validators) ifRange() string {
+	//Weak entity tags can not be used for range requests
+	if v.ETag != "" && !strings.HasPrefix(v.ETag, "W/") {
+		return v.ETag
+	}
+	return v.LastModified
+}
+
+//Resume continues the download of the url into a file left by an earlier run. The content of the file
+//is kept if the server reports that the file still matches the validators and is replaced otherwise.
+//Received is called with the validators of the server before any data is written so they can be stored with the file
+func Resume(ctx context.Context, url string, file *os.File, validators Validators, received func(Validators) error, progress func(current, total int64)) error {
+	var written int64
+	if validators.ifRange() != "" {
+		offset, err := file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		written = offset
+	} else if err := restart(file); err != nil {
+		return err
+	}
+
+	return retry(ctx, func() (bool, error) {
+		header := http.Header{}
+		if written > 0 && validators.ifRange() == "" {
+			//Without a validator the server could send the rest of a different file so the download starts again
+			if err := restart(file); err != nil {
+				return false, err
+			}
+			written = 0
+		}
+		if written > 0 {
+			header.Set("Range", "bytes="+strconv.FormatInt(written, 10)+"-")
+			header.Set("If-Range", validators.ifRange())
+		}
+
+		reqCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		resp, err := do(reqCtx, url, header)
+		if err != nil {
+			return true, err
+		}
+		defer resp.Body.Close()
+
+		total := resp.ContentLength
+		switch {
+		case resp.StatusCode == http.StatusPartialContent && written > 0:
+			start, ok := rangeStart(resp.Header.Get("Content-Range"))
+			if !ok || start != written {
+				//The server sent a different part of the file so the download starts again
+				if err := restart(file); err != nil {
+					return false, err
+				}
+				written = 0
+				return true, errcode.New(errcode.DownloadFailed, "cmd/internal: Could not resume download of '%s' because the server sent the range '%s'", url, resp.Header.Get("Content-Range"))
+			}
+			if total >= 0 {
+				total += written
+			}
+		case resp.StatusCode == http.StatusOK:
+			//The server ignored the range so the download starts from the beginning
+			if err := restart(file); err != nil {
+				return false, err
+			}
+			written = 0
+			validators = Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+		case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && written > 0:
+			//The file on the server is shorter than the partial download so it changed
+			if err := restart(file); err != nil {
+				return false, err
+			}
+			written = 0
+			return true, errcode.New(errcode.DownloadFailed, "cmd/internal: Could not resume download of '%s': %s", url, resp.Status)
+		case retryable(resp.StatusCode):
+			return true, errcode.New(errcode.DownloadFailed, "cmd/internal: Could not download '%s': %s", url, resp.Status)
+		default:
+			return false, errcode.New(errcode.DownloadFailed, "cmd/internal: Could not download '%s': %s", url, resp.Status)
+		}
+
+		if received != nil {
+			if err := received(validators); err != nil {
+				return false, err
+			}
+		}
+		if progress != nil {
+			progress(written, total)
+		}
+
+		body := &timeoutReader{reader: resp.Body, timer: time.AfterFunc(readTimeout, cancel)}
+		defer body.timer.Stop()
+
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := body.Read(buf)
+			if n > 0 {
+				if _, werr := file.Write(buf[:n]); werr != nil {
+					return false, werr
+				}
+				written += int64(n)
+				if progress != nil {
+					progress(written, total)
+				}
+			}
+
+			if err == io.EOF {
+				if total >= 0 && written < total {
+					return true, io.ErrUnexpectedEOF
+				}
+				return false, nil
+			}
+			if err != nil {
+				return ctx.Err() == nil, err
+			}
+		}
+	})
+}
+
+//rangeStart returns the first byte of a Content-Range header like "bytes 100-199/200"
+func rangeStart(value string) (int64, bool) {
+	if !strings.HasPrefix(value, "bytes ") {
+		return 0, false
+	}
+	value = strings.TrimPrefix(value, "bytes ")
+	end := strings.IndexByte(value, '-')
+	if end < 0 {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(value[:end], 10, 64)
+	return start, err == nil
+}
+
+func restart(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.Seek(0, io.SeekStart)
+	return err
+}
+
+//timeoutReader cancels the request if no data arrives within the read timeout
+type timeoutReader struct {
+	reader io.Reader
+	timer  *time.Timer
+}
+
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.timer.Reset(readTimeout)
+	return n, err
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/journal"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/snapshot"
+)
+
+//LogEntry is an operation recorded in the journal of a game
+type LogEntry = journal.Entry
+
+//LogChange is a mod changed by an operation
+type LogChange = journal.Change
+
+//LogFilter selects entries of the journal
+type LogFilter = journal.Filter
+
+//Log returns the operations which changed the mods of the game starting with the newest one.
+//The game is a path or the id of a registered game. The current game is used if it is empty
+func Log(game string, filter LogFilter) ([]*LogEntry, error) {
+	dir, err := Options{Game: game}.game()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := journal.Read(dir, filter)
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not read journal because an error occured in %s", err.Error())
+	}
+	if entries == nil {
+		entries = []*LogEntry{}
+	}
+	return entries, nil
+}
+
+//ParseSince reads a point in time like "24h", "2006-01-02" or a RFC 3339 timestamp
+func ParseSince(value string) (time.Time, error) {
+	return journal.ParseSince(value, time.Now())
+}
+
+//begin records the state of the game before a mutating operation and takes a snapshot of it.
+//The operation is added to the journal once it finishes unless it is a dry run
+func (stats *Stats) begin(operation string, args []string) error {
+	game := stats.game
+	before, err := local.GetMods(game)
+	if err != nil {
+		return errcode.Wrap(err, "cmd: Could not list installed mods because an error occured in %s", err.Error())
+	}
+
+	stats.before = before
+	if stats.dryRun() {
+		//Dry runs change nothing so there is nothing to restore or record
+		return nil
+	}
+
+	stats.entry = &journal.Entry{
+		Time:      time.Now(),
+		Game:      game,
+		Operation: operation,
+		Args:      args,
+	}
+
+	if _, err := snapshot.Take(game, operation, args); err != nil {
+		return errcode.Wrap(err, "cmd: Could not create snapshot because an error occured in %s", err.Error())
+	}
+	return nil
+}
+
+//record adds the finished operation to the journal
+func (stats *Stats) record(err error) {
+	entry := stats.entry
+	entry.Warnings = stats.Warnings
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Code = errcode.Of(err)
+	}
+
+	after, afterErr := local.GetMods(stats.game)
+	if afterErr != nil {
+		stats.AddWarning(fmt.Sprintf("cmd: Could not record the changed mods because an error occured in %s", afterErr.Error()))
+		after = stats.before
+	}
+	entry.Changes = journal.Diff(stats.before, after)
+
+	if err := journal.Append(entry); err != nil {
+		stats.AddWarning(fmt.Sprintf("cmd: Could not write journal because an error occured in %s", err.Error()))
+	}
+}
@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
 )
 
 //List prints a list of all available mods
@@ -15,7 +18,72 @@ func List() {
 		os.Exit(1)
 	}
 
+	game, _ := currentGame()
 	for _, mod := range data.Mods {
-		fmt.Printf("%s %s\n", mod.Version, mod.Name)
+		installed, err := local.GetMod(game, mod.Name)
+		switch {
+		case err != nil:
+			fmt.Printf("%s %s\n", mod.Version, mod.Name)
+		case installed.Enabled:
+			fmt.Printf("%s %s (installed)\n", mod.Version, mod.Name)
+		default:
+			fmt.Printf("%s %s (disabled)\n", mod.Version, mod.Name)
+		}
 	}
 }
+
+//AvailableMods returns the names of all mods in the mod database
+func AvailableMods() ([]string, error) {
+	data, err := global.FetchModData()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, mod := range data.Mods {
+		names = append(names, mod.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+//InstalledMods returns the names of all installed mods including disabled ones
+func InstalledMods() ([]string, error) {
+	game, err := currentGame()
+	if err != nil {
+		return nil, err
+	}
+
+	mods, err := local.GetMods(game)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, mod := range mods {
+		names = append(names, mod.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+//LocalMod is an installed mod with its packaging and location
+type LocalMod = local.Mod
+
+//LocalMods returns all installed mods including disabled ones sorted by name
+func LocalMods() ([]LocalMod, error) {
+	game, err := currentGame()
+	if err != nil {
+		return nil, err
+	}
+
+	mods, err := local.GetMods(game)
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not list installed mods because an error occured in %s", err.Error())
+	}
+
+	sort.SliceStable(mods, func(i, j int) bool {
+		return mods[i].Name < mods[j].Name
+	})
+	return mods, nil
+}
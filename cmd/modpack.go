@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/install"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/modpack"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/tools"
+)
+
+//PackOptions configures what is stored in an exported modpack
+type PackOptions struct {
+	//Bundle stores the archives of the mods in a zip file so the modpack can be imported offline
+	Bundle bool
+	//Tools stores the versions of the installed tools like CCLoader
+	Tools bool
+}
+
+//ExportPack writes the installed mods of the game as a modpack to the file
+func ExportPack(file string, pack PackOptions, opts Options) (*Stats, error) {
+	game, err := opts.game()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := global.FetchModData(); err != nil {
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd: Could not download mod data because an error occured in %s", err.Error())
+	}
+
+	mods, err := local.GetMods(game)
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not list installed mods because an error occured in %s", err.Error())
+	}
+
+	stats := newStats(opts, game)
+	p := &modpack.Pack{Format: modpack.Format, Mods: []modpack.Mod{}}
+	for _, mod := range mods {
+		m := modpack.Mod{
+			Name:    mod.Name,
+			Version: mod.Version,
+			Enabled: mod.Enabled,
+		}
+
+		dbMod, err := global.GetMod(mod.Name)
+		if err == nil && dbMod.Version == mod.Version {
+			m.Source = dbMod.ArchiveLink
+			m.Hash = dbMod.Hash.Sha256
+			if dbMod.Dir != nil {
+				m.Dir = dbMod.Dir.Any
+			}
+		} else if !pack.Bundle {
+			stats.AddWarning(fmt.Sprintf("cmd: Could not find the source of '%s' %s so it can only be imported if the mod database still has it", mod.Name, mod.Version))
+		}
+
+		p.Mods = append(p.Mods, m)
+	}
+
+	if pack.Tools {
+		if version, err := tools.Find("ccloader").Current(); err == nil {
+			p.Tools = map[string]string{"ccloader": version}
+		} else {
+			stats.AddWarning(fmt.Sprintf("cmd: Could not find the version of CCLoader because an error occured in %s", err.Error()))
+		}
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return stats.finish(err)
+	}
+
+	if pack.Bundle {
+		err = writeBundle(out, p, mods, stats)
+	} else {
+		err = p.Write(out)
+	}
+	out.Close()
+
+	if err != nil {
+		//Do not leave a broken modpack behind
+		os.Remove(file)
+	}
+	return stats.finish(err)
+}
+
+//writeBundle downloads the archives of all mods of the modpack and stores them next to it.
+//Mods without a source are not in the mod database so their installed files are stored instead
+func writeBundle(w io.Writer, p *modpack.Pack, mods []local.Mod, stats *Stats) error {
+	var changes []*change
+	var packed []local.Mod
+	for i := range p.Mods {
+		m := p.Mods[i]
+		if m.Source == "" {
+			packed = append(packed, mods[i])
+			continue
+		}
+
+		changes = append(changes, &change{
+			name: m.Name,
+			fetch: func(ctx context.Context, progress install.Progress) (*install.Package, error) {
+				return install.DownloadMod(ctx, stats.game, m.Global(), progress)
+			},
+		})
+	}
+
+	download(changes, stats)
+
+	bundle := modpack.NewBundleWriter(w)
+	for i, ch := range changes {
+		if ch.err != nil {
+			closeChanges(changes[i:])
+			return ch.err
+		}
+
+		err := writeArchive(bundle, ch.name, ch.pkg.WriteArchive, p)
+		ch.pkg.Close()
+		if err != nil {
+			closeChanges(changes[i+1:])
+			return errcode.Wrap(err, "cmd: Could not bundle '%s' because an error occured in %s", ch.name, err.Error())
+		}
+	}
+
+	for _, mod := range packed {
+		if err := writeArchive(bundle, mod.Name, mod.WriteArchive, p); err != nil {
+			return errcode.Wrap(err, "cmd: Could not bundle '%s' because an error occured in %s", mod.Name, err.Error())
+		}
+	}
+
+	return bundle.Close(p)
+}
+
+//writeArchive adds the archive of the mod to the bundle and records its hash if the mod database did not know it
+func writeArchive(bundle *modpack.BundleWriter, name string, write func(io.Writer) error, p *modpack.Pack) error {
+	w, err := bundle.Archive(name)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	if err := write(io.MultiWriter(w, hash)); err != nil {
+		return err
+	}
+
+	for i := range p.Mods {
+		if p.Mods[i].Name == name && p.Mods[i].Hash == "" {
+			p.Mods[i].Hash = hex.EncodeToString(hash.Sum(nil))
+		}
+	}
+	return nil
+}
+
+//ImportPack installs exactly the mods of the modpack into the game.
+//Installed mods which are not part of the modpack are disabled
+func ImportPack(file string, opts Options) (*Stats, error) {
+	game, err := opts.game()
+	if err != nil {
+		return nil, err
+	}
+
+	p, bundle, err := modpack.Open(file)
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not read modpack because an error occured in %s", err.Error())
+	}
+	if bundle != nil {
+		defer bundle.Close()
+	}
+
+	stats := newStats(opts, game)
+	if err := stats.begin("import", []string{file}); err != nil {
+		return stats.finish(err)
+	}
+
+	mods := stats.before
+	checkTools(p, stats)
+
+	installed := map[string]local.Mod{}
+	for _, mod := range mods {
+		installed[mod.Name] = mod
+	}
+
+	for _, mod := range mods {
+		if !inPack(p, mod.Name) && mod.Enabled {
+			if err := toggle(mod.Name, false, stats); err != nil {
+				return stats.finish(err)
+			}
+		}
+	}
+
+	var changes []*change
+	for _, m := range p.Mods {
+		mod, ok := installed[m.Name]
+		if ok && mod.Version == m.Version {
+			continue
+		}
+
+		ch, err := planPackMod(m, bundle, stats)
+		if err != nil {
+			return stats.finish(err)
+		}
+		if ch != nil {
+			ch.update = ok
+			//Dry runs do not download the mods so the version is taken from the modpack
+			ch.manifest = local.Mod{Name: m.Name, Version: m.Version}
+			changes = append(changes, ch)
+		}
+	}
+
+	if stats.dryRun() {
+		for _, ch := range changes {
+			stats.plan(plannedChange(ch, stats))
+		}
+		changes = nil
+	}
+
+	//The modpack already contains the dependencies in the right versions so they are not resolved again
+	download(changes, stats)
+	if _, err := applyAll(changes, stats); err != nil {
+		return stats.finish(err)
+	}
+
+	for _, m := range p.Mods {
+		if err := toggle(m.Name, m.Enabled, stats); err != nil {
+			return stats.finish(err)
+		}
+	}
+
+	return stats.finish(nil)
+}
+
+//planPackMod returns the change which installs the mod from the bundle, its source or the mod database
+func planPackMod(m modpack.Mod, bundle *modpack.Bundle, stats *Stats) (*change, error) {
+	//The archive is only opened when the mod is fetched so dry runs and failed plans do not leave it open
+	if bundle != nil && bundle.Contains(m.Name) {
+		return &change{
+			name: m.Name,
+			fetch: func(ctx context.Context, progress install.Progress) (*install.Package, error) {
+				r, _, err := bundle.Archive(m.Name)
+				if err != nil {
+					return nil, errcode.Wrap(err, "cmd: Could not read '%s' from bundle because an error occured in %s", m.Name, err.Error())
+				}
+				defer r.Close()
+				return install.Open(ctx, stats.game, m.Global(), r, progress)
+			},
+		}, nil
+	}
+
+	if m.Source != "" {
+		return &change{
+			name: m.Name,
+			fetch: func(ctx context.Context, progress install.Progress) (*install.Package, error) {
+				return install.DownloadMod(ctx, stats.game, m.Global(), progress)
+			},
+		}, nil
+	}
+
+	if _, err := global.FetchModData(); err != nil {
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd: Could not download mod data because an error occured in %s", err.Error())
+	}
+
+	dbMod, err := global.GetMod(m.Name)
+	if err != nil || dbMod.Version != m.Version {
+		stats.addCodedWarning(errcode.ModNotFound, fmt.Sprintf("cmd: Could not find a source for '%s' %s", m.Name, m.Version))
+		return nil, nil
+	}
+	return &change{name: m.Name}, nil
+}
+
+//checkTools warns about tools which differ from the ones the modpack was made with
+func checkTools(p *modpack.Pack, stats *Stats) {
+	for name, version := range p.Tools {
+		tool := tools.Find(name)
+		if tool == nil {
+			stats.AddWarning(fmt.Sprintf("cmd: Modpack requires unknown tool '%s'", name))
+			continue
+		}
+
+		current, err := tool.Current()
+		if err != nil || current != version {
+			stats.AddWarning(fmt.Sprintf("cmd: Modpack was made with %s %s but %s is installed", name, version, current))
+		}
+	}
+}
+
+func inPack(p *modpack.Pack, name string) bool {
+	for _, m := range p.Mods {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
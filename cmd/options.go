@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/install"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//Types of events reported while an operation is running
+const (
+	EventResolving   = "resolving"
+	EventDownloading = install.StageDownloading
+	EventExtracting  = install.StageExtracting
+	EventCopying     = install.StageCopying
+	EventDone        = "done"
+	EventWarning     = "warning"
+	EventError       = "error"
+)
+
+//Event describes the progress of an operation
+type Event struct {
+	Type    string `json:"type"`
+	Mod     string `json:"mod,omitempty"`
+	Current int64  `json:"current,omitempty"`
+	Total   int64  `json:"total,omitempty"`
+	Message string `json:"message,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
+
+//Options change how an operation is executed
+type Options struct {
+	//Context cancels the operation when it is done. Defaults to context.Background()
+	Context context.Context
+	//Progress is called for every event of the operation if set. It may be called concurrently
+	Progress func(Event)
+	//Workers is the amount of concurrent downloads. Defaults to DefaultWorkers
+	Workers int
+	//DryRun only resolves the operation and records the changes in Stats.Plan without changing the game
+	DryRun bool
+	//Downloads is used to share downloaded mods with other operations if set
+	Downloads *Downloads
+	//Changelog adds the release notes between the installed and the new version to planned updates
+	Changelog bool
+	//Game is the path of the game folder or the id of a registered game the operation changes.
+	//Defaults to the game selected by the game flag, the configuration or the working directory
+	Game string
+}
+
+//game returns the folder of the game the operation changes
+func (opts Options) game() (string, error) {
+	game, err := local.FindGame(opts.Game)
+	if err != nil {
+		return "", errcode.New(errcode.GameNotFound, "cmd: Could not find game folder")
+	}
+	return game, nil
+}
+
+//currentGame returns the folder of the game selected by the game flag, the configuration or the working directory
+func currentGame() (string, error) {
+	return Options{}.game()
+}
+
+func newStats(opts Options, game string) *Stats {
+	//The mod folders may have been changed by other programs since the last operation
+	local.Refresh(game)
+	return &Stats{DryRun: opts.DryRun, options: opts, game: game}
+}
+
+func (stats *Stats) context() context.Context {
+	if stats.options.Context == nil {
+		return context.Background()
+	}
+	return stats.options.Context
+}
+
+func (stats *Stats) report(event Event) {
+	if stats.options.Progress != nil {
+		stats.options.Progress(event)
+	}
+}
+
+//progress returns the installation progress of a mod
+func (stats *Stats) progress(name string) install.Progress {
+	return func(stage string, current, total int64) {
+		stats.report(Event{
+			Type:    stage,
+			Mod:     name,
+			Current: current,
+			Total:   total,
+		})
+	}
+}
+
+//finish reports the end of the operation
+func (stats *Stats) finish(err error) (*Stats, error) {
+	if stats.entry != nil {
+		stats.record(err)
+	}
+
+	if err != nil {
+		stats.report(Event{Type: EventError, Message: err.Error(), Code: ErrorCode(err)})
+	} else {
+		stats.report(Event{Type: EventDone})
+	}
+	return stats, err
+}
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
@@ -10,12 +12,19 @@ import (
 
 //Outdated displays old mods and their new version
 func Outdated() {
-	if _, err := local.GetGame(); err != nil {
+	OutdatedWith(Options{})
+}
+
+//OutdatedWith displays old mods and their new version. If Options.Changelog is set
+//the release notes of the versions since the installed one are displayed as well
+func OutdatedWith(opts Options) {
+	game, err := opts.game()
+	if err != nil {
 		fmt.Printf("Could not find game folder. Make sure you executed the command inside the game folder.\n")
 		return
 	}
 
-	mods, err := local.GetMods()
+	mods, err := local.GetMods(game)
 	if err != nil {
 		fmt.Printf("Could not list mods because of an error in %s\n", err.Error())
 		os.Exit(1)
@@ -36,6 +45,45 @@ func Outdated() {
 			}
 
 			fmt.Printf("%s   %s   %s\n", new.Version, mod.Version, mod.Name)
+
+			if opts.Changelog {
+				printChanges(opts, new, mod.Version)
+			}
+		}
+	}
+}
+
+func printChanges(opts Options, mod global.Mod, from string) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	changes, err := mod.Changes(ctx, from)
+	if err != nil {
+		fmt.Printf("    Could not read changelog because of an error in %s\n", err.Error())
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Println("    No changelog available")
+		return
+	}
+	PrintReleases(changes, "    ")
+}
+
+//PrintReleases prints the release notes with the given indentation
+func PrintReleases(releases []Release, indent string) {
+	for _, release := range releases {
+		if release.Date != "" {
+			fmt.Printf("%s%s (%s)\n", indent, release.Version, release.Date)
+		} else {
+			fmt.Printf("%s%s\n", indent, release.Version)
+		}
+		if release.Notes == "" {
+			continue
+		}
+		for _, line := range strings.Split(release.Notes, "\n") {
+			fmt.Printf("%s  %s\n", indent, line)
 		}
 	}
 }
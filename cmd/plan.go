@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/config"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/install"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
+//DefaultWorkers is the amount of concurrent downloads if none is configured
+const DefaultWorkers = config.DefaultParallel
+
+//change is a mod which has to be installed or updated
+type change struct {
+	name   string
+	update bool
+
+	//fetch replaces the download from the mod database if set
+	fetch func(ctx context.Context, progress install.Progress) (*install.Package, error)
+
+	pkg *install.Package
+	err error
+
+	//manifest and size are set by dry runs instead of the package
+	manifest local.Mod
+	size     int64
+}
+
+//execute downloads all changes concurrently and applies them one after another.
+//Dependencies of the applied mods are resolved and executed afterwards
+func execute(changes []*change, stats *Stats) error {
+	if stats.dryRun() {
+		return simulate(changes, stats)
+	}
+
+	for len(changes) > 0 {
+		changes = unique(changes)
+		download(changes, stats)
+
+		applied, err := applyAll(changes, stats)
+		if err != nil {
+			return err
+		}
+
+		changes = nil
+		for _, mod := range applied {
+			deps, err := planDependencies(mod, stats)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, deps...)
+		}
+	}
+	return nil
+}
+
+//download fetches the packages of all changes using the configured amount of workers
+func download(changes []*change, stats *Stats) {
+	parallel(changes, stats, func(ch *change) {
+		if ch.fetch != nil {
+			ch.pkg, ch.err = ch.fetch(stats.context(), stats.progress(ch.name))
+		} else if stats.options.Downloads != nil {
+			ch.pkg, ch.err = stats.options.Downloads.get(stats.context(), stats.game, ch.name, stats.progress(ch.name))
+		} else {
+			ch.pkg, ch.err = install.Download(stats.context(), stats.game, ch.name, stats.progress(ch.name))
+		}
+		if ch.err != nil {
+			ch.err = errcode.Wrap(ch.err, "cmd: Could not %s '%s' because an error occured in %s", ch.verb(), ch.name, ch.err.Error())
+		}
+	})
+}
+
+//parallel calls fn for all changes using the configured amount of workers
+func parallel(changes []*change, stats *Stats, fn func(ch *change)) {
+	workers := stats.options.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+		if cfg, err := config.Effective(stats.game); err == nil && cfg.Parallel > 0 {
+			workers = cfg.Parallel
+		}
+	}
+
+	queue := make(chan *change)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ch := range queue {
+				fn(ch)
+			}
+		}()
+	}
+
+	for _, ch := range changes {
+		queue <- ch
+	}
+	close(queue)
+	wg.Wait()
+}
+
+//applyAll installs the downloaded changes in order and stops at the first error
+func applyAll(changes []*change, stats *Stats) ([]local.Mod, error) {
+	var applied []local.Mod
+	for i, ch := range changes {
+		if ch.err != nil {
+			closeChanges(changes[i:])
+			return applied, ch.err
+		}
+
+		mod, ok, err := apply(ch, stats)
+		if err != nil {
+			closeChanges(changes[i+1:])
+			return applied, err
+		}
+		if ok {
+			applied = append(applied, mod)
+		}
+	}
+	return applied, nil
+}
+
+//apply installs a downloaded change into the game folder
+func apply(ch *change, stats *Stats) (local.Mod, bool, error) {
+	defer ch.pkg.Close()
+
+	if err := stats.context().Err(); err != nil {
+		return local.Mod{}, false, err
+	}
+
+	if err := ch.pkg.Apply(ch.update); err != nil {
+		return local.Mod{}, false, errcode.Wrap(err, "cmd: Could not %s '%s' because an error occured in %s", ch.verb(), ch.name, err.Error())
+	}
+
+	if ch.update {
+		stats.Updated++
+	}
+
+	mod, err := local.GetMod(stats.game, ch.name)
+	if err != nil {
+		if ch.update {
+			stats.AddWarning(fmt.Sprintf("cmd: Updated '%s' but it seems to be an invalid mod", ch.name))
+		} else {
+			stats.AddWarning(fmt.Sprintf("cmd: Installed '%s' but it seems to be an invalid mod", ch.name))
+		}
+		return local.Mod{}, false, nil
+	}
+
+	if !ch.update {
+		stats.Installed++
+	}
+	return mod, true, nil
+}
+
+func (ch *change) verb() string {
+	if ch.update {
+		return "update"
+	}
+	return "install"
+}
+
+//unique removes changes of mods which are already part of the list
+func unique(changes []*change) []*change {
+	seen := map[string]bool{}
+	var res []*change
+	for _, ch := range changes {
+		if seen[ch.name] {
+			continue
+		}
+		seen[ch.name] = true
+		res = append(res, ch)
+	}
+	return res
+}
+
+func closeChanges(changes []*change) {
+	for _, ch := range changes {
+		if ch.pkg != nil {
+			ch.pkg.Close()
+		}
+	}
+}
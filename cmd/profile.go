@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/profile"
+)
+
+//ProfileInfo describes a saved profile
+type ProfileInfo struct {
+	Name string       `json:"name"`
+	Mods []ProfileMod `json:"mods"`
+}
+
+//ProfileMod is a mod recorded in a profile
+type ProfileMod struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Enabled bool   `json:"enabled"`
+}
+
+//CreateProfile records the installed mods of the game as a profile
+func CreateProfile(name string, override bool, opts Options) (*ProfileInfo, error) {
+	game, err := opts.game()
+	if err != nil {
+		return nil, err
+	}
+
+	if !override && profile.Exists(name) {
+		return nil, errcode.New(errcode.AlreadyExists, "cmd: Profile '%s' already exists", name)
+	}
+
+	mods, err := local.GetMods(game)
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not list installed mods because an error occured in %s", err.Error())
+	}
+
+	p := &profile.Profile{Name: name, Mods: []profile.Mod{}}
+	for _, mod := range mods {
+		p.Mods = append(p.Mods, profile.Mod{
+			Name:    mod.Name,
+			Version: mod.Version,
+			Enabled: mod.Enabled,
+		})
+	}
+
+	if err := p.Save(); err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not save profile '%s' because an error occured in %s", name, err.Error())
+	}
+	return profileInfo(p), nil
+}
+
+//Profiles lists all saved profiles
+func Profiles() ([]*ProfileInfo, error) {
+	profiles, err := profile.List()
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*ProfileInfo{}
+	for _, p := range profiles {
+		res = append(res, profileInfo(p))
+	}
+	return res, nil
+}
+
+//DeleteProfile removes a saved profile. The installed mods are not changed
+func DeleteProfile(name string) error {
+	return profile.Delete(name)
+}
+
+//ExportProfile writes the profile as JSON
+func ExportProfile(name string, w io.Writer) error {
+	p, err := profile.Load(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(profileInfo(p))
+}
+
+//SwitchProfile enables, disables and installs mods until the game matches the profile.
+//Mods which are not part of the profile are disabled, not removed. If the database does not have the version
+//of a mod the profile wants the other mods are switched anyway and an error lists the mismatched mods
+func SwitchProfile(name string, opts Options) (*Stats, error) {
+	game, err := opts.game()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := profile.Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := newStats(opts, game)
+	if err := stats.begin("profile switch", []string{name}); err != nil {
+		return stats.finish(err)
+	}
+
+	mods := stats.before
+	for _, mod := range mods {
+		if _, ok := p.Find(mod.Name); !ok && mod.Enabled {
+			if err := toggle(mod.Name, false, stats); err != nil {
+				return stats.finish(err)
+			}
+		}
+	}
+
+	var changes []*change
+	for _, want := range p.Mods {
+		ch, err := planProfileMod(want, stats)
+		if err != nil {
+			return stats.finish(err)
+		}
+		if ch != nil {
+			changes = append(changes, ch)
+		}
+	}
+
+	if err := execute(changes, stats); err != nil {
+		return stats.finish(err)
+	}
+
+	for _, want := range p.Mods {
+		if err := toggle(want.Name, want.Enabled, stats); err != nil {
+			return stats.finish(err)
+		}
+	}
+
+	if len(stats.Mismatched) > 0 {
+		var names []string
+		for _, mod := range stats.Mismatched {
+			names = append(names, fmt.Sprintf("'%s' %s", mod.Name, mod.Version))
+		}
+		return stats.finish(errcode.New(errcode.ModNotFound, "cmd: Could not switch to profile '%s' completely because %s could not be installed", name, strings.Join(names, ", ")))
+	}
+	return stats.finish(nil)
+}
+
+//planProfileMod returns the change needed to get the mod of a profile into the game
+func planProfileMod(want profile.Mod, stats *Stats) (*change, error) {
+	mod, err := local.GetMod(stats.game, want.Name)
+	if err == nil && mod.Version == want.Version {
+		return nil, nil
+	}
+
+	if _, err := global.FetchModData(); err != nil {
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd: Could not download mod data because an error occured in %s", err.Error())
+	}
+
+	newest, dbErr := global.GetMod(want.Name)
+	switch {
+	case dbErr != nil:
+		stats.mismatch(want, fmt.Sprintf("cmd: Profile wants '%s' %s but it is not available", want.Name, want.Version))
+	case newest.Version != want.Version:
+		stats.mismatch(want, fmt.Sprintf("cmd: Profile wants '%s' %s but only %s is available", want.Name, want.Version, newest.Version))
+	}
+
+	if err != nil {
+		return planInstall(want.Name, stats)
+	}
+
+	if dbErr == nil && newest.Version == want.Version {
+		return planUpdate(want.Name, stats)
+	}
+	return nil, nil
+}
+
+//mismatch records that the version of the mod the profile wants can not be installed
+func (stats *Stats) mismatch(want profile.Mod, warning string) {
+	stats.Mismatched = append(stats.Mismatched, ProfileMod{
+		Name:    want.Name,
+		Version: want.Version,
+		Enabled: want.Enabled,
+	})
+	stats.addCodedWarning(errcode.ModNotFound, warning)
+}
+
+func profileInfo(p *profile.Profile) *ProfileInfo {
+	info := &ProfileInfo{Name: p.Name, Mods: []ProfileMod{}}
+	for _, mod := range p.Mods {
+		info.Mods = append(info.Mods, ProfileMod{
+			Name:    mod.Name,
+			Version: mod.Version,
+			Enabled: mod.Enabled,
+		})
+	}
+	return info
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/snapshot"
+)
+
+//Snapshot is a saved state of the mods of the game
+type Snapshot struct {
+	ID        int       `json:"id"`
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Args      []string  `json:"args,omitempty"`
+}
+
+//History lists the snapshots of the game starting with the newest one
+func History() ([]*Snapshot, error) {
+	game, err := currentGame()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := snapshot.List(game)
+	if err != nil {
+		return nil, errcode.Wrap(err, "cmd: Could not list snapshots because an error occured in %s", err.Error())
+	}
+
+	res := []*Snapshot{}
+	for _, snap := range snapshots {
+		res = append(res, snapshotInfo(snap))
+	}
+	return res, nil
+}
+
+//Rollback restores the snapshot with the id or the newest one if the id is 0.
+//The current state is saved as a new snapshot first so the rollback can be undone
+func Rollback(id int) (*Snapshot, error) {
+	snap, _, err := RollbackWith(id, Options{})
+	return snap, err
+}
+
+//RollbackWith restores a snapshot using the given options
+func RollbackWith(id int, opts Options) (*Snapshot, *Stats, error) {
+	game, err := opts.game()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var snap *snapshot.Snapshot
+	if id == 0 {
+		snapshots, err := snapshot.List(game)
+		if err != nil {
+			return nil, nil, errcode.Wrap(err, "cmd: Could not list snapshots because an error occured in %s", err.Error())
+		}
+		if len(snapshots) == 0 {
+			return nil, nil, errcode.New(errcode.NotFound, "cmd: Could not roll back because there are no snapshots")
+		}
+		snap = snapshots[0]
+	} else {
+		snap, err = snapshot.Find(game, id)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	stats := newStats(opts, game)
+	if err := stats.begin("rollback", []string{strconv.Itoa(snap.ID)}); err != nil {
+		stats, err = stats.finish(err)
+		return nil, stats, err
+	}
+
+	if stats.dryRun() {
+		mods, err := snap.Mods()
+		if err != nil {
+			stats, err = stats.finish(errcode.Wrap(err, "cmd: Could not read snapshot %d because an error occured in %s", snap.ID, err.Error()))
+			return nil, stats, err
+		}
+
+		planDiff(stats.before, mods, stats)
+		stats, err = stats.finish(nil)
+		return snapshotInfo(snap), stats, err
+	}
+
+	if err := snap.Restore(); err != nil {
+		stats, err = stats.finish(errcode.Wrap(err, "cmd: Could not restore snapshot %d because an error occured in %s", snap.ID, err.Error()))
+		return nil, stats, err
+	}
+
+	stats, err = stats.finish(nil)
+	return snapshotInfo(snap), stats, err
+}
+
+func snapshotInfo(snap *snapshot.Snapshot) *Snapshot {
+	return &Snapshot{
+		ID:        snap.ID,
+		Time:      snap.Time,
+		Operation: snap.Operation,
+		Args:      snap.Args,
+	}
+}
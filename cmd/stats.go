@@ -1,15 +1,47 @@
 package cmd
 
+import (
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/journal"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
+)
+
 //Stats contains the statistics about the installed mods
 type Stats struct {
 	Installed int `json:"installed"`
 	Updated   int `json:"updated"`
 	Removed   int `json:"removed"`
+	Enabled   int `json:"enabled,omitempty"`
+	Disabled  int `json:"disabled,omitempty"`
 
 	Warnings []string `json:"warnings,omitempty"`
+	//Mismatched lists the mods of a profile whose wanted version could not be installed
+	Mismatched []ProfileMod `json:"mismatched,omitempty"`
+
+	//DryRun is set if nothing was changed and Plan lists the changes instead
+	DryRun bool            `json:"dryRun,omitempty"`
+	Plan   []PlannedChange `json:"plan,omitempty"`
+
+	options Options
+	//game is the folder of the game the operation changes
+	game string
+	//entry is the journal entry of a mutating operation
+	entry  *journal.Entry
+	before []local.Mod
 }
 
 //AddWarning to the statistics
 func (stats *Stats) AddWarning(warning string) {
+	stats.addCodedWarning("", warning)
+}
+
+//addCodedWarning adds a warning whose event tells clients what went wrong
+func (stats *Stats) addCodedWarning(code errcode.Code, warning string) {
 	stats.Warnings = append(stats.Warnings, warning)
+	stats.report(Event{Type: EventWarning, Message: warning, Code: string(code)})
+}
+
+//ErrorCode returns the machine readable code of an error returned by an operation
+func ErrorCode(err error) string {
+	return string(errcode.Of(err))
 }
@@ -4,46 +4,74 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/tools"
 )
 
 //Uninstall removes a mod from a directory
 func Uninstall(args []string) (*Stats, error) {
-	if _, err := local.GetGame(); err != nil {
-		return nil, fmt.Errorf("cmd: Could not find game folder")
+	return UninstallWith(args, Options{})
+}
+
+//UninstallWith removes mods using the given options
+func UninstallWith(args []string, opts Options) (*Stats, error) {
+	game, err := opts.game()
+	if err != nil {
+		return nil, err
 	}
 
-	stats := &Stats{}
+	stats := newStats(opts, game)
+	if err := stats.begin("uninstall", args); err != nil {
+		return stats.finish(err)
+	}
 	for _, name := range args {
-		mod, err := local.GetMod(name)
+		if err := stats.context().Err(); err != nil {
+			return stats.finish(err)
+		}
+
+		mod, err := local.GetMod(stats.game, name)
 		if err != nil {
 			err = uninstallTool(name, stats)
 			if err != nil {
-				return stats, err
+				return stats.finish(err)
 			}
 			continue
 		}
 
+		if stats.dryRun() {
+			stats.plan(PlannedChange{Mod: name, Action: ActionRemove, From: mod.Version})
+			continue
+		}
+
 		err = os.RemoveAll(mod.BasePath)
+		local.Invalidate()
 		if err != nil {
 			stats.AddWarning(fmt.Sprintf("cmd: Could not remove mod '%s' because of an error in %s", name, err.Error()))
+			continue
 		}
 
 		stats.Removed++
 	}
 
-	return stats, nil
+	return stats.finish(nil)
 }
 
 func uninstallTool(name string, stats *Stats) error {
 	tool := tools.Find(name)
 	if tool == nil {
-		stats.AddWarning(fmt.Sprintf("cmd: Could not find mod or tool '%s'", name))
+		stats.addCodedWarning(errcode.NotInstalled, fmt.Sprintf("cmd: Could not find mod or tool '%s'", name))
+		return nil
+	}
+
+	if stats.dryRun() {
+		stats.plan(PlannedChange{Mod: name, Action: ActionRemove})
 		return nil
 	}
 
 	err := tool.Uninstall()
+	//Tools like CCLoader bring the configuration of the mod folders
+	local.Invalidate()
 	if err != nil {
 		return err
 	}
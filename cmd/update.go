@@ -3,44 +3,58 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/errcode"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/global"
-	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/install"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/local"
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd/internal/tools"
 )
 
 //Update a mod
 func Update(args []string) (*Stats, error) {
-	if _, err := local.GetGame(); err != nil {
-		return nil, fmt.Errorf("cmd: Could not find game folder")
+	return UpdateWith(args, Options{})
+}
+
+//UpdateWith updates mods using the given options
+func UpdateWith(args []string, opts Options) (*Stats, error) {
+	game, err := opts.game()
+	if err != nil {
+		return nil, err
 	}
 
-	_, err := global.FetchModData()
+	_, err = global.FetchModData()
 	if err != nil {
-		return nil, fmt.Errorf("cmd: Could not download mod data because an error occured in %s", err.Error())
+		return nil, errcode.New(errcode.DatabaseUnavailable, "cmd: Could not download mod data because an error occured in %s", err.Error())
 	}
 
+	stats := newStats(opts, game)
+	if err := stats.begin("update", args); err != nil {
+		return stats.finish(err)
+	}
 	if len(args) == 0 {
-		return updateOutdated()
+		return stats.finish(updateOutdated(stats))
 	}
 
-	stats := &Stats{}
+	var changes []*change
 	for _, name := range args {
-		if err := updateMod(name, stats); err != nil {
-			return stats, err
+		ch, err := planUpdate(name, stats)
+		if err != nil {
+			return stats.finish(err)
+		}
+		if ch != nil {
+			changes = append(changes, ch)
 		}
 	}
 
-	return stats, nil
+	return stats.finish(execute(changes, stats))
 }
 
-func updateOutdated() (*Stats, error) {
-	mods, err := local.GetMods()
+func updateOutdated(stats *Stats) error {
+	mods, err := local.GetMods(stats.game)
 	if err != nil {
-		return nil, fmt.Errorf("cmd: Could not list installed mods because and error occured in %s", err.Error())
+		return errcode.Wrap(err, "cmd: Could not list installed mods because and error occured in %s", err.Error())
 	}
 
-	stats := &Stats{}
+	var changes []*change
 	for _, mod := range mods {
 		if _, err := global.GetMod(mod.Name); err != nil {
 			continue
@@ -48,50 +62,54 @@ func updateOutdated() (*Stats, error) {
 
 		outdated, err := mod.Outdated()
 		if err != nil {
-			return stats, fmt.Errorf("cmd: Could not check if the mod was outdated because an error occured in %s", err.Error())
+			return errcode.Wrap(err, "cmd: Could not check if the mod was outdated because an error occured in %s", err.Error())
 		}
 
 		if !outdated {
 			continue
 		}
 
-		if err := updateMod(mod.Name, stats); err != nil {
-			return stats, err
+		ch, err := planUpdate(mod.Name, stats)
+		if err != nil {
+			return err
+		}
+		if ch != nil {
+			changes = append(changes, ch)
 		}
 	}
 
-	return stats, nil
+	return execute(changes, stats)
 }
 
-func updateMod(name string, stats *Stats) error {
-	if _, err := local.GetMod(name); err != nil {
-		return updateTool(name, stats)
+//planUpdate returns the change updating the mod. Tools are updated right away
+func planUpdate(name string, stats *Stats) (*change, error) {
+	if err := stats.context().Err(); err != nil {
+		return nil, err
 	}
 
-	if _, err := global.GetMod(name); err != nil {
-		stats.AddWarning(fmt.Sprintf("cmd: Could find '%s'", name))
-		return nil
-	}
+	stats.report(Event{Type: EventResolving, Mod: name})
 
-	if err := install.Install(name, true); err != nil {
-		return fmt.Errorf("cmd: Could not update '%s' because an error occured in %s", name, err.Error())
+	if _, err := local.GetMod(stats.game, name); err != nil {
+		return nil, updateTool(name, stats)
 	}
 
-	stats.Updated++
-
-	mod, err := local.GetMod(name)
-	if err != nil {
-		stats.AddWarning(fmt.Sprintf("cmd: Updated '%s' but it seems to be an invalid mod", name))
-		return nil
+	if _, err := global.GetMod(name); err != nil {
+		stats.addCodedWarning(errcode.ModNotFound, fmt.Sprintf("cmd: Could find '%s'", name))
+		return nil, nil
 	}
 
-	return installDependencies(mod, stats)
+	return &change{name: name, update: true}, nil
 }
 
 func updateTool(name string, stats *Stats) error {
 	tool := tools.Find(name)
 	if tool == nil {
-		stats.AddWarning(fmt.Sprintf("cmd: Could not update '%s' because it was not installed", name))
+		stats.addCodedWarning(errcode.NotInstalled, fmt.Sprintf("cmd: Could not update '%s' because it was not installed", name))
+		return nil
+	}
+
+	if stats.dryRun() {
+		stats.plan(PlannedChange{Mod: name, Action: ActionUpdate})
 		return nil
 	}
 
@@ -0,0 +1,4 @@
+package cmd
+
+//Version of the tool
+const Version = "1.2.0-dev"
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd/api"
+)
+
+//root is the ccmu command itself
+var root = &command{
+	name:    "ccmu",
+	summary: "CrossCode Mod Updater installs, updates and removes mods of CrossCode",
+}
+
+func init() {
+	root.subcommands = []*command{
+		{
+			name:     "install",
+			aliases:  []string{"i"},
+			args:     "<mod name...>",
+			summary:  "Installs one or more mods",
+			minArgs:  1,
+			maxArgs:  -1,
+			flags:    allGamesFlag,
+			complete: availableMods,
+			run: func(args []string) {
+				forEachGame(func(opts cmd.Options) (*cmd.Stats, error) {
+					return runConfirmed(cmd.InstallWith, args, opts, *yes)
+				})
+			},
+		},
+		{
+			name:     "uninstall",
+			aliases:  []string{"remove", "delete"},
+			args:     "<mod name...>",
+			summary:  "Uninstall one or more mods",
+			minArgs:  1,
+			maxArgs:  -1,
+			complete: installedMods,
+			run: func(args []string) {
+				runConfirmed(cmd.UninstallWith, args, options(), *yes)
+			},
+		},
+		{
+			name:    "update",
+			args:    "[mod name...]",
+			summary: "Updates one or more mods or all outdated ones",
+			maxArgs: -1,
+			flags: func(fs *flag.FlagSet) {
+				allGamesFlag(fs)
+				fs.BoolVar(&showChanges, "show-changes", false, "Shows the release notes of the new versions before updating")
+			},
+			complete: installedMods,
+			run: func(args []string) {
+				forEachGame(func(opts cmd.Options) (*cmd.Stats, error) {
+					opts.Changelog = showChanges
+					return runConfirmed(cmd.UpdateWith, args, opts, *yes)
+				})
+			},
+		},
+		{
+			name:     "enable",
+			args:     "<mod name...>",
+			summary:  "Enables one or more disabled mods",
+			minArgs:  1,
+			maxArgs:  -1,
+			complete: installedMods,
+			run: func(args []string) {
+				printStatsAndError(cmd.EnableWith(args, options()))
+			},
+		},
+		{
+			name:     "disable",
+			args:     "<mod name...>",
+			summary:  "Disables one or more mods without removing them",
+			minArgs:  1,
+			maxArgs:  -1,
+			complete: installedMods,
+			run: func(args []string) {
+				printStatsAndError(cmd.DisableWith(args, options()))
+			},
+		},
+		{
+			name:    "list",
+			summary: "Lists all mods that the tool knows about",
+			flags: func(fs *flag.FlagSet) {
+				allGamesFlag(fs)
+				fs.BoolVar(&listInstalled, "installed", false, "Lists the installed mods with their packaging and location instead")
+			},
+			run: func(args []string) {
+				forEachGame(func(opts cmd.Options) (*cmd.Stats, error) {
+					if listInstalled {
+						return nil, printLocalMods()
+					}
+					cmd.List()
+					return nil, nil
+				})
+			},
+		},
+		{
+			name:    "outdated",
+			summary: "Show the names and versions of outdated mods",
+			flags: func(fs *flag.FlagSet) {
+				allGamesFlag(fs)
+				fs.BoolVar(&showChanges, "changelog", false, "Shows the release notes of the versions since the installed one")
+			},
+			run: func(args []string) {
+				forEachGame(func(opts cmd.Options) (*cmd.Stats, error) {
+					opts.Changelog = showChanges
+					cmd.OutdatedWith(opts)
+					return nil, nil
+				})
+			},
+		},
+		profileCommand(),
+		exportCommand(),
+		importCommand(),
+		historyCommand(),
+		rollbackCommand(),
+		logCommand(),
+		configCommand(),
+		gamesCommand(),
+		doctorCommand(),
+		apiCommand(),
+		completionCommand(),
+		completeCommand(),
+		{
+			name:    "version",
+			summary: "Display the version of this tool",
+			run: func(args []string) {
+				printVersion()
+			},
+		},
+		{
+			name:    "help",
+			args:    "[command...]",
+			summary: "Display this message or the help of a command",
+			maxArgs: -1,
+			complete: func() []string {
+				return commandNames(root)
+			},
+			run: func(args []string) {
+				runHelp(args)
+			},
+		},
+	}
+}
+
+func apiCommand() *command {
+	var port int
+	var host, socket string
+	return &command{
+		name:    "api",
+		summary: "Starts the api server",
+		flags: func(fs *flag.FlagSet) {
+			fs.IntVar(&port, "port", 9392, "Sets the `port` which the api server listens on")
+			fs.StringVar(&host, "host", "", "Sets the `host` which the api server listens on")
+			fs.StringVar(&socket, "socket", "", "Listens on the unix domain socket at `path` instead")
+		},
+		run: func(args []string) {
+			if socket != "" {
+				api.StartUnix(socket)
+			} else {
+				api.StartAt(host, port)
+			}
+		},
+	}
+}
+
+//listInstalled is set by the --installed flag of list
+var listInstalled bool
+
+//showChanges is set by the --show-changes flag of update and the --changelog flag of outdated
+var showChanges bool
+
+func printLocalMods() error {
+	mods, err := cmd.LocalMods()
+	if err != nil {
+		fmt.Printf("ERROR in %s\n", err.Error())
+		return err
+	}
+
+	for _, mod := range mods {
+		state := "enabled"
+		if !mod.Enabled {
+			state = "disabled"
+		}
+		fmt.Printf("%-10s %-20s %-8s %-9s %s\n", mod.Version, mod.Name, state, mod.Packaging, mod.BasePath)
+	}
+	return nil
+}
+
+//runHelp prints the help of the command with the path of names
+func runHelp(names []string) {
+	path := []*command{root}
+	for _, name := range names {
+		c := path[len(path)-1].find(name)
+		if c == nil {
+			unknownCommand(path, name)
+			os.Exit(1)
+		}
+		path = append(path, c)
+	}
+	printCommandHelp(path)
+}
+
+func availableMods() []string {
+	names, _ := cmd.AvailableMods()
+	return names
+}
+
+func installedMods() []string {
+	names, _ := cmd.InstalledMods()
+	return names
+}
+
+//commandNames returns the names of the visible subcommands
+func commandNames(c *command) []string {
+	var names []string
+	for _, sub := range c.subcommands {
+		if !sub.hidden {
+			names = append(names, sub.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+const bashCompletion = `# bash completion for ccmu
+_ccmu() {
+	local IFS=$'\n'
+	COMPREPLY=($(ccmu __complete "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _ccmu ccmu
+`
+
+const zshCompletion = `#compdef ccmu
+_ccmu() {
+	local -a candidates
+	candidates=("${(@f)$(ccmu __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	if [[ -n "${candidates[1]}" ]]; then
+		compadd -a candidates
+	else
+		_files
+	fi
+}
+compdef _ccmu ccmu
+`
+
+const fishCompletion = `# fish completion for ccmu
+function __ccmu_complete
+	set -l words (commandline -opc)
+	set -e words[1]
+	ccmu __complete $words (commandline -ct) 2>/dev/null
+end
+complete -c ccmu -a '(__ccmu_complete)'
+`
+
+func completionCommand() *command {
+	script := func(name, content string) *command {
+		return &command{
+			name:    name,
+			summary: "Prints the completion script for " + name,
+			run: func(args []string) {
+				fmt.Print(content)
+			},
+		}
+	}
+
+	return &command{
+		name:    "completion",
+		summary: "Prints shell completion scripts, e.g. source <(ccmu completion bash)",
+		subcommands: []*command{
+			script("bash", bashCompletion),
+			script("zsh", zshCompletion),
+			script("fish", fishCompletion),
+		},
+	}
+}
+
+//completeCommand is called by the completion scripts with the words of the command line.
+//The last word is the one being completed
+func completeCommand() *command {
+	return &command{
+		name:    "__complete",
+		maxArgs: -1,
+		hidden:  true,
+		rawArgs: true,
+		run: func(args []string) {
+			for _, candidate := range complete(args) {
+				fmt.Println(candidate)
+			}
+		},
+	}
+}
+
+//complete returns the candidates for the last word
+func complete(words []string) []string {
+	current := ""
+	if len(words) > 0 {
+		current, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	path := []*command{root}
+	var args []string
+	for i := 0; i < len(words); i++ {
+		c := path[len(path)-1]
+		word := words[i]
+
+		if strings.HasPrefix(word, "-") {
+			//Flags like --game are applied since they change the candidates
+			fs := c.flagSet(path)
+			name := strings.TrimLeft(word, "-")
+			if parts := strings.SplitN(name, "=", 2); len(parts) == 2 {
+				fs.Set(parts[0], parts[1])
+			} else if takesValue(c, fs, word) && i+1 < len(words) {
+				fs.Set(name, words[i+1])
+				i++
+			}
+			continue
+		}
+
+		if len(args) == 0 {
+			if sub := c.find(word); sub != nil {
+				path = append(path, sub)
+				continue
+			}
+		}
+		args = append(args, word)
+	}
+
+	c := path[len(path)-1]
+	fs := c.flagSet(path)
+
+	//Values of flags are completed by the shell except for ids of registered games
+	var candidates []string
+	if len(words) > 0 && strings.HasPrefix(words[len(words)-1], "-") && takesValue(c, fs, words[len(words)-1]) {
+		if strings.TrimLeft(words[len(words)-1], "-") != "game" {
+			return nil
+		}
+		candidates = cmd.GameIDs()
+	}
+
+	switch {
+	case candidates != nil:
+	case strings.HasPrefix(current, "-"):
+		fs.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, "--"+f.Name)
+		})
+	case len(c.subcommands) > 0 && len(args) == 0:
+		candidates = commandNames(c)
+	case c.complete != nil && (c.maxArgs < 0 || len(args) < c.maxArgs):
+		candidates = c.complete()
+	}
+
+	var res []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			res = append(res, candidate)
+		}
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+func configCommand() *command {
+	var game bool
+	gameFlag := func(fs *flag.FlagSet) {
+		fs.BoolVar(&game, "local", false, "Changes the configuration file of the game instead of the one of the user")
+	}
+
+	return &command{
+		name:    "config",
+		summary: "Shows and changes settings",
+		subcommands: []*command{
+			{
+				name:     "get",
+				args:     "<key>",
+				summary:  "Prints the value of a setting",
+				minArgs:  1,
+				maxArgs:  1,
+				complete: cmd.SettingNames,
+				run: func(args []string) {
+					setting, err := cmd.GetSetting(args[0])
+					exitOnError(err)
+					fmt.Println(setting.Value)
+				},
+			},
+			{
+				name:     "set",
+				args:     "<key> <value>",
+				summary:  "Changes a setting",
+				minArgs:  2,
+				maxArgs:  2,
+				flags:    gameFlag,
+				complete: cmd.SettingNames,
+				run: func(args []string) {
+					exitOnError(cmd.SetSetting(args[0], args[1], game))
+				},
+			},
+			{
+				name:     "unset",
+				args:     "<key>",
+				summary:  "Resets a setting to its default",
+				minArgs:  1,
+				maxArgs:  1,
+				flags:    gameFlag,
+				complete: cmd.SettingNames,
+				run: func(args []string) {
+					exitOnError(cmd.SetSetting(args[0], "", game))
+				},
+			},
+			{
+				name:    "list",
+				summary: "Lists all settings with their values and where they come from",
+				run: func(args []string) {
+					settings, err := cmd.Settings()
+					exitOnError(err)
+					for _, setting := range settings {
+						fmt.Printf("%-14s %-10s %s\n", setting.Name, "("+setting.Source+")", setting.Value)
+					}
+				},
+			},
+		},
+	}
+}
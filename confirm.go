@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+type operation func(args []string, opts cmd.Options) (*cmd.Stats, error)
+
+//runConfirmed shows the plan of the operation and asks before executing it.
+//Nothing is asked if --yes is set, the operation is a dry run or stdin is not a terminal.
+//The plan is still shown in these cases if it contains release notes.
+//The printed result is returned. It is nil if the operation was aborted
+func runConfirmed(op operation, args []string, opts cmd.Options, yes bool) (*cmd.Stats, error) {
+	unattended := yes || !isTerminal(os.Stdin)
+	if opts.DryRun || (unattended && !opts.Changelog) {
+		return printed(op(args, opts))
+	}
+
+	//The archives downloaded for the plan are kept so they are not downloaded again
+	if opts.Downloads == nil {
+		if downloads, err := cmd.NewDownloads(); err == nil {
+			defer downloads.Close()
+			opts.Downloads = downloads
+		}
+	}
+
+	dryRun := opts
+	dryRun.DryRun = true
+	stats, err := op(args, dryRun)
+	if err != nil {
+		return printed(stats, err)
+	}
+
+	printWarnings(stats.Warnings)
+	if len(stats.Plan) == 0 {
+		fmt.Println("Nothing to do")
+		return nil, nil
+	}
+
+	fmt.Println("The following changes will be made")
+	printPlan(stats.Plan)
+	if !unattended && !ask("Proceed?") {
+		fmt.Println("Aborted")
+		return nil, nil
+	}
+
+	return printed(op(args, opts))
+}
+
+//printed prints the result of an operation and returns it
+func printed(stats *cmd.Stats, err error) (*cmd.Stats, error) {
+	printStatsAndError(stats, err)
+	return stats, err
+}
+
+//ask reads a yes or no answer from stdin. Anything but yes is a no
+func ask(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
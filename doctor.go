@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+func doctorCommand() *command {
+	var fix bool
+	return &command{
+		name:    "doctor",
+		summary: "Checks the installed mods for problems",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&fix, "fix", false, "Fixes the problems which can be fixed automatically")
+		},
+		run: func(args []string) {
+			problems, stats, err := cmd.Doctor(fix, options())
+			if stats != nil {
+				printWarnings(stats.Warnings)
+			}
+			exitOnError(err)
+
+			if len(problems) == 0 {
+				fmt.Println("No problems found")
+				return
+			}
+
+			unsolved := 0
+			for _, problem := range problems {
+				status := ""
+				switch {
+				case problem.Fixed:
+					status = " (fixed)"
+				case problem.FixError != "":
+					status = " (fix failed)"
+				case problem.Fixable:
+					status = " (fixable)"
+				}
+				fmt.Printf("[%s] %s%s\n", problem.Kind, problem.Message, status)
+				if !problem.Fixed {
+					unsolved++
+					fmt.Printf("  Suggestion: %s\n", problem.Suggestion)
+				}
+			}
+
+			fmt.Printf("Found %d problems, %d unsolved\n", len(problems), unsolved)
+			if unsolved > 0 && !fix {
+				fmt.Println("Run 'ccmu doctor --fix' to fix the fixable problems")
+			}
+			if unsolved > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+}
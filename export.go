@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+func exportCommand() *command {
+	var pack cmd.PackOptions
+	return &command{
+		name:    "export",
+		args:    "<file>",
+		summary: "Saves the installed mods as a modpack",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&pack.Bundle, "bundle", false, "Includes the mod archives so the modpack can be imported offline")
+			fs.BoolVar(&pack.Tools, "tools", false, "Includes the version of CCLoader")
+		},
+		run: func(args []string) {
+			printStatsAndError(cmd.ExportPack(args[0], pack, options()))
+		},
+	}
+}
+
+func importCommand() *command {
+	return &command{
+		name:    "import",
+		args:    "<file>",
+		summary: "Installs exactly the mods of a modpack or bundle",
+		minArgs: 1,
+		maxArgs: 1,
+		run: func(args []string) {
+			printStatsAndError(cmd.ImportPack(args[0], options()))
+		},
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+func gamesCommand() *command {
+	return &command{
+		name:    "games",
+		summary: "Manages the known installations of the game",
+		subcommands: []*command{
+			{
+				name:    "scan",
+				summary: "Registers installations found in the Steam, GOG and itch folders",
+				run: func(args []string) {
+					games, err := cmd.ScanGames()
+					exitOnError(err)
+					if len(games) == 0 {
+						fmt.Println("No new installations found")
+					}
+					for _, game := range games {
+						fmt.Printf("Added %s (%s)\n", game.ID, game.Path)
+					}
+				},
+			},
+			{
+				name:    "add",
+				args:    "<path> [id]",
+				summary: "Registers the game in the folder",
+				minArgs: 1,
+				maxArgs: 2,
+				run: func(args []string) {
+					game := cmd.Game{Path: args[0]}
+					if len(args) == 2 {
+						game.ID = args[1]
+					}
+					added, err := cmd.AddGame(game)
+					exitOnError(err)
+					fmt.Printf("Added %s (%s)\n", added.ID, added.Path)
+				},
+			},
+			{
+				name:     "remove",
+				aliases:  []string{"rm"},
+				args:     "<id>",
+				summary:  "Forgets a registered game without touching its files",
+				minArgs:  1,
+				maxArgs:  1,
+				complete: cmd.GameIDs,
+				run: func(args []string) {
+					exitOnError(cmd.RemoveGame(args[0]))
+				},
+			},
+			{
+				name:    "list",
+				summary: "Lists the registered games",
+				run: func(args []string) {
+					games, def, err := cmd.Games()
+					exitOnError(err)
+					for _, game := range games {
+						marker := " "
+						if game.ID == def {
+							marker = "*"
+						}
+						fmt.Printf("%s %-12s %-6s %s\n", marker, game.ID, game.Source, game.Path)
+					}
+				},
+			},
+			{
+				name:     "default",
+				args:     "<id>",
+				summary:  "Uses the registered game if no game is given",
+				minArgs:  1,
+				maxArgs:  1,
+				complete: cmd.GameIDs,
+				run: func(args []string) {
+					exitOnError(cmd.SetDefaultGame(args[0]))
+				},
+			},
+		},
+	}
+}
+
+//allGames is set by the --all-games flag of commands which support several games
+var allGames bool
+
+func allGamesFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&allGames, "all-games", false, "Runs the command for every registered game")
+}
+
+//selectedGames returns the games the command runs for or nil if it only runs for the current game
+func selectedGames() []string {
+	if allGames {
+		ids := cmd.GameIDs()
+		if len(ids) == 0 {
+			exitOnError(fmt.Errorf("main: No games are registered. Run 'ccmu games scan' or 'ccmu games add' first"))
+		}
+		return uniqueGames(ids)
+	}
+
+	if games := uniqueGames(game.all); len(games) > 1 {
+		return games
+	}
+	return nil
+}
+
+//uniqueGames drops games which select the same folder as an earlier one, e.g. an id and the path of the same game
+func uniqueGames(selections []string) []string {
+	seen := map[string]bool{}
+	var games []string
+	for _, selection := range selections {
+		path := cmd.GamePath(selection)
+		if !seen[path] {
+			seen[path] = true
+			games = append(games, selection)
+		}
+	}
+	return games
+}
+
+//forEachGame runs the command once for every selected game. The games share downloads and
+//the results of all games are summed up at the end
+func forEachGame(run func(opts cmd.Options) (*cmd.Stats, error)) {
+	games := selectedGames()
+	if games == nil {
+		run(options())
+		return
+	}
+
+	downloads, err := cmd.NewDownloads()
+	exitOnError(err)
+	defer downloads.Close()
+
+	total := &cmd.Stats{}
+	var summary bool
+	var failed []string
+	for i, id := range games {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("== %s ==\n", id)
+
+		game.current = id
+		opts := options()
+		opts.Downloads = downloads
+
+		stats, err := run(opts)
+		if err != nil {
+			failed = append(failed, id)
+		}
+		if stats != nil {
+			summary = true
+			total.Installed += stats.Installed
+			total.Updated += stats.Updated
+			total.Removed += stats.Removed
+			total.Plan = append(total.Plan, stats.Plan...)
+			total.DryRun = stats.DryRun
+		}
+	}
+
+	fmt.Println()
+	if summary && total.DryRun {
+		fmt.Printf("All %d games: %d changes would be made\n", len(games), len(total.Plan))
+	} else if summary {
+		fmt.Printf("All %d games: installed %d, updated %d, removed %d\n", len(games), total.Installed, total.Updated, total.Removed)
+	}
+	if len(failed) > 0 {
+		downloads.Close()
+		fmt.Printf("Failed for %d of %d games: %s\n", len(failed), len(games), strings.Join(failed, ", "))
+		os.Exit(1)
+	}
+}
@@ -1,19 +1,102 @@
 package main
 
-import "fmt"
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
 
+//printHelp prints the commands of the tool
 func printHelp() {
-	fmt.Println("Usage: ccmu [options] [command] <args...>")
-	fmt.Println("")
-	fmt.Println("Options:")
-	fmt.Println("  --game <path>         Sets the game folder used for operations")
+	printCommandHelp([]*command{root})
+}
+
+//printCommandHelp prints the usage, subcommands and flags of the last command of the path
+func printCommandHelp(path []*command) {
+	c := path[len(path)-1]
+
+	fmt.Printf("Usage: %s\n", usage(path))
+	if c.summary != "" {
+		fmt.Println("")
+		fmt.Println(c.summary)
+	}
+	if len(c.aliases) > 0 {
+		fmt.Println("")
+		fmt.Printf("Aliases: %s\n", strings.Join(c.aliases, ", "))
+	}
+
+	if len(c.subcommands) > 0 {
+		fmt.Println("")
+		fmt.Println("Commands:")
+		for _, sub := range c.subcommands {
+			if !sub.hidden {
+				printColumns(strings.TrimSpace(sub.name+" "+sub.args), sub.summary)
+			}
+		}
+	}
+
+	if c.flags != nil {
+		fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+		c.flags(fs)
+		fmt.Println("")
+		fmt.Println("Options:")
+		printFlags(fs)
+	}
+
 	fmt.Println("")
-	fmt.Println("Commands:")
-	fmt.Println("  install <mod name>    Installs one or more mods")
-	fmt.Println("  uninstall <mod name>  Uninstall one or more mods")
-	fmt.Println("  update [mod name]     Updates one or more mods")
-	fmt.Println("  list                  Lists all mods that the tool knows about")
-	fmt.Println("  outdated              Show the names and versions of outdated mods")
-	fmt.Println("  version               Display the version of this tool")
-	fmt.Println("  help                  Display this message")
+	fmt.Println("Global options:")
+	printFlags(flag.CommandLine)
+
+	if len(c.subcommands) > 0 {
+		fmt.Println("")
+		fmt.Printf("Run '%s <command> --help' for more information about a command\n", commandName(path))
+	}
+}
+
+//usage returns the usage line of the last command of the path
+func usage(path []*command) string {
+	c := path[len(path)-1]
+
+	line := commandName(path) + " [options]"
+	if len(c.subcommands) > 0 {
+		line += " <command>"
+	}
+	if c.args != "" {
+		line += " " + c.args
+	}
+	return line
+}
+
+//shorthands maps flags to their single letter alias
+var shorthands = map[string]string{
+	"yes": "y",
+}
+
+func printFlags(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		for _, short := range shorthands {
+			if f.Name == short {
+				return
+			}
+		}
+
+		name, usage := flag.UnquoteUsage(f)
+
+		left := "--" + f.Name
+		if short, ok := shorthands[f.Name]; ok {
+			left = "-" + short + ", " + left
+		}
+		if name != "" {
+			left += " <" + name + ">"
+		}
+		printColumns(left, usage)
+	})
+}
+
+func printColumns(left, right string) {
+	if len(left) > 24 {
+		fmt.Printf("  %s\n  %-24s %s\n", left, "", right)
+		return
+	}
+	fmt.Printf("  %-24s %s\n", left, right)
 }
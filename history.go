@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+func historyCommand() *command {
+	return &command{
+		name:    "history",
+		summary: "Lists the snapshots taken before mods were changed",
+		run: func(args []string) {
+			printHistory()
+		},
+	}
+}
+
+func rollbackCommand() *command {
+	return &command{
+		name:    "rollback",
+		args:    "[id]",
+		summary: "Restores a snapshot, by default the newest one",
+		maxArgs: 1,
+		complete: func() []string {
+			var ids []string
+			snapshots, _ := cmd.History()
+			for _, snap := range snapshots {
+				ids = append(ids, strconv.Itoa(snap.ID))
+			}
+			return ids
+		},
+		run: func(args []string) {
+			runRollback(args, options())
+		},
+	}
+}
+
+func printHistory() {
+	snapshots, err := cmd.History()
+	exitOnError(err)
+
+	if len(snapshots) == 0 {
+		fmt.Println("No snapshots")
+		return
+	}
+
+	for _, snap := range snapshots {
+		fmt.Printf("%4d  %s  %s %s\n", snap.ID, snap.Time.Format("2006-01-02 15:04:05"), snap.Operation, strings.Join(snap.Args, " "))
+	}
+}
+
+func runRollback(args []string, opts cmd.Options) {
+	id := 0
+	if len(args) > 0 {
+		var err error
+		id, err = strconv.Atoi(args[0])
+		if err != nil || id <= 0 {
+			fmt.Printf("%s is not a snapshot id\n", args[0])
+			os.Exit(1)
+		}
+	}
+
+	snap, stats, err := cmd.RollbackWith(id, opts)
+	exitOnError(err)
+
+	if stats.DryRun {
+		printStatsAndError(stats, nil)
+		return
+	}
+	fmt.Printf("Restored snapshot %d taken before %s\n", snap.ID, snap.Operation)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+func logCommand() *command {
+	var since string
+	var filter cmd.LogFilter
+	return &command{
+		name:    "log",
+		summary: "Lists the operations which changed mods",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&since, "since", "", "Only shows operations after a `time` like 24h or 2006-01-02")
+			fs.StringVar(&filter.Mod, "mod", "", "Only shows operations which changed the mod with this `name`")
+			fs.IntVar(&filter.Limit, "limit", 0, "Shows at most `count` operations")
+		},
+		run: func(args []string) {
+			var err error
+			filter.Since, err = cmd.ParseSince(since)
+			exitOnError(err)
+			printLog(filter)
+		},
+	}
+}
+
+func printLog(filter cmd.LogFilter) {
+	entries, err := cmd.Log("", filter)
+	exitOnError(err)
+
+	if len(entries) == 0 {
+		fmt.Println("No operations recorded")
+		return
+	}
+
+	for _, entry := range entries {
+		fmt.Printf("%s  %s %s\n", entry.Time.Format("2006-01-02 15:04:05"), entry.Operation, strings.Join(entry.Args, " "))
+		for _, change := range entry.Changes {
+			switch {
+			case change.From == "":
+				fmt.Printf("  %s %s %s\n", change.Action, change.Mod, change.To)
+			case change.To == "" || change.From == change.To:
+				fmt.Printf("  %s %s %s\n", change.Action, change.Mod, change.From)
+			default:
+				fmt.Printf("  %s %s %s -> %s\n", change.Action, change.Mod, change.From, change.To)
+			}
+		}
+		for _, warning := range entry.Warnings {
+			fmt.Printf("  Warning in %s\n", warning)
+		}
+		if entry.Error != "" {
+			fmt.Printf("  ERROR in %s\n", entry.Error)
+		}
+	}
+}
@@ -6,64 +6,125 @@ import (
 	"os"
 
 	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
-	"github.com/CCDirectLink/CCUpdaterCLI/cmd/api"
 )
 
-func main() {
-	flag.String("game", "", "if set it overrides the path of the game")
-
-	port := flag.Int("port", 9392, "the port which the api server listens on")
-	host := flag.String("host", "", "the host which the api server listens on")
+//Global flags which are accepted by every command
+var (
+	workers = flag.Int("parallel", 0, "Sets the `count` of concurrent downloads. Defaults to the parallel setting")
+	dryRun  = flag.Bool("dry-run", false, "Prints what a command would change without changing anything")
+	yes     = flag.Bool("yes", false, "Changes mods without asking for confirmation")
+	game    = &gameFlag{}
+)
 
-	flag.Parse()
+func init() {
+	flag.Var(game, "game", "Sets the `path` of the game folder or the id of a registered game used for operations. Can be repeated")
+	flag.String("proxy", "", "Sets the proxy `url` used for downloads")
+	flag.BoolVar(yes, "y", false, "Shorthand for --yes")
+}
 
+func main() {
 	if len(os.Args) == 1 {
 		printHelp()
 		return
 	}
 
-	op := flag.Arg(0)
-	args := flag.Args()[1:]
-
-	switch op {
-	case "install",
-		"i":
-		printStatsAndError(cmd.Install(args))
-	case "remove",
-		"delete",
-		"uninstall":
-		printStatsAndError(cmd.Uninstall(args))
-	case "update":
-		printStatsAndError(cmd.Update(args))
-	case "list":
-		cmd.List()
-	case "outdated":
-		cmd.Outdated()
-	case "api":
-		api.StartAt(*host, *port)
-	case "version":
-		printVersion()
-	case "help":
-		printHelp()
-	default:
-		fmt.Printf("%s\n is not a command", op)
-		printHelp()
-		os.Exit(1)
+	execute([]*command{root}, os.Args[1:])
+}
+
+//gameFlag selects the game used by operations. Every game given on the command line is
+//collected so commands can run once per game
+type gameFlag struct {
+	current string
+	all     []string
+}
+
+func (g *gameFlag) String() string {
+	return g.current
+}
+
+//Set selects the game and collects it so commands can run for every game given on the command line
+func (g *gameFlag) Set(value string) error {
+	g.current = value
+	g.all = append(g.all, value)
+	return nil
+}
+
+//options returns the operation options configured by the global flags and the progress setting
+func options() cmd.Options {
+	opts := cmd.Options{}
+	progress, _ := cmd.GetSetting("progress")
+	if progress == nil || progress.Value != "none" {
+		printer := newProgressPrinter(os.Stdout)
+		if progress != nil && progress.Value == "plain" {
+			printer.tty = false
+		}
+		opts = printer.options()
 	}
+
+	opts.Workers = *workers
+	opts.DryRun = *dryRun
+	return opts
 }
 
 func printStatsAndError(stats *cmd.Stats, err error) {
-	if stats != nil && stats.Warnings != nil {
-		for _, warning := range stats.Warnings {
-			fmt.Printf("Warning in %s\n", warning)
-		}
+	if stats != nil {
+		printWarnings(stats.Warnings)
 	}
 
 	if err != nil {
 		fmt.Printf("ERROR in %s\n", err.Error())
 	}
 
-	if stats != nil {
+	if stats != nil && stats.DryRun {
+		if len(stats.Plan) == 0 {
+			fmt.Println("Dry run: nothing would change")
+		} else {
+			fmt.Println("Dry run: the following changes would be made")
+			printPlan(stats.Plan)
+		}
+	} else if stats != nil {
 		fmt.Printf("Installed %d, updated %d, removed %d\n", stats.Installed, stats.Updated, stats.Removed)
+		if stats.Enabled > 0 || stats.Disabled > 0 {
+			fmt.Printf("Enabled %d, disabled %d\n", stats.Enabled, stats.Disabled)
+		}
+	}
+}
+
+func printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Printf("Warning in %s\n", warning)
+	}
+}
+
+func printPlan(plan []cmd.PlannedChange) {
+	var size int64
+	for _, change := range plan {
+		line := fmt.Sprintf("  %-8s %s", change.Action, change.Mod)
+		switch {
+		case change.From != "" && change.To != "" && change.From != change.To:
+			line += fmt.Sprintf(" %s -> %s", change.From, change.To)
+		case change.To != "":
+			line += " " + change.To
+		case change.From != "":
+			line += " " + change.From
+		}
+		if change.Size > 0 {
+			line += fmt.Sprintf(" (%s)", formatBytes(change.Size))
+			size += change.Size
+		}
+		fmt.Println(line)
+		cmd.PrintReleases(change.Changes, "      ")
+	}
+
+	if size > 0 {
+		fmt.Printf("Total download size: %s\n", formatBytes(size))
+	}
+}
+
+//exitOnError prints the error and exits if there is one
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Printf("ERROR in %s\n", err.Error())
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+func profileCommand() *command {
+	return &command{
+		name:    "profile",
+		summary: "Manages named sets of mods",
+		subcommands: []*command{
+			{
+				name:    "create",
+				args:    "<name>",
+				summary: "Saves the installed mods as a profile",
+				minArgs: 1,
+				maxArgs: 1,
+				run: func(args []string) {
+					p, err := cmd.CreateProfile(args[0], false, options())
+					exitOnError(err)
+					fmt.Printf("Created profile %s with %d mods\n", p.Name, len(p.Mods))
+				},
+			},
+			{
+				name:    "list",
+				summary: "Lists all saved profiles",
+				run: func(args []string) {
+					profiles, err := cmd.Profiles()
+					exitOnError(err)
+					for _, p := range profiles {
+						fmt.Printf("%s (%d mods)\n", p.Name, len(p.Mods))
+					}
+				},
+			},
+			{
+				name:     "switch",
+				args:     "<name>",
+				summary:  "Installs, enables and disables mods to match a profile",
+				minArgs:  1,
+				maxArgs:  1,
+				complete: profileNames,
+				run: func(args []string) {
+					printStatsAndError(cmd.SwitchProfile(args[0], options()))
+				},
+			},
+			{
+				name:     "delete",
+				args:     "<name>",
+				summary:  "Deletes a saved profile",
+				minArgs:  1,
+				maxArgs:  1,
+				complete: profileNames,
+				run: func(args []string) {
+					exitOnError(cmd.DeleteProfile(args[0]))
+				},
+			},
+			{
+				name:     "export",
+				args:     "<name>",
+				summary:  "Prints a profile as JSON",
+				minArgs:  1,
+				maxArgs:  1,
+				complete: profileNames,
+				run: func(args []string) {
+					exitOnError(cmd.ExportProfile(args[0], os.Stdout))
+				},
+			},
+		},
+	}
+}
+
+func profileNames() []string {
+	var names []string
+	profiles, _ := cmd.Profiles()
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
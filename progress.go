@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
+
+const (
+	barWidth         = 30
+	redrawInterval   = 100 * time.Millisecond
+	plainLogInterval = 2 * time.Second
+)
+
+//progressPrinter renders operation events as progress bars on a terminal
+//and as periodic lines otherwise
+type progressPrinter struct {
+	mutex sync.Mutex
+	out   io.Writer
+	tty   bool
+
+	mods  []*modProgress
+	drawn int
+	last  time.Time
+}
+
+type modProgress struct {
+	name    string
+	stage   string
+	current int64
+	total   int64
+	started time.Time
+	logged  time.Time
+	done    bool
+}
+
+func newProgressPrinter(file *os.File) *progressPrinter {
+	return &progressPrinter{
+		out: file,
+		tty: isTerminal(file),
+	}
+}
+
+//options returns the operation options reporting to this printer
+func (p *progressPrinter) options() cmd.Options {
+	return cmd.Options{Progress: p.handle}
+}
+
+func (p *progressPrinter) handle(event cmd.Event) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch event.Type {
+	case cmd.EventDone, cmd.EventError:
+		for _, mod := range p.mods {
+			mod.done = true
+		}
+		p.draw(true)
+		return
+	case cmd.EventWarning:
+		return
+	}
+
+	if event.Mod == "" {
+		return
+	}
+
+	mod := p.find(event.Mod)
+	force := mod.stage != event.Type
+	if force {
+		mod.stage = event.Type
+		mod.started = time.Now()
+	}
+	mod.current = event.Current
+	mod.total = event.Total
+
+	if event.Type == cmd.EventCopying {
+		mod.done = true
+		force = true
+	}
+
+	if p.tty {
+		p.draw(force)
+	} else {
+		p.log(mod, force)
+	}
+}
+
+func (p *progressPrinter) find(name string) *modProgress {
+	for _, mod := range p.mods {
+		if mod.name == name && !mod.done {
+			return mod
+		}
+	}
+
+	mod := &modProgress{name: name}
+	p.mods = append(p.mods, mod)
+	return mod
+}
+
+//log prints a plain line on stage changes and every few seconds while downloading
+func (p *progressPrinter) log(mod *modProgress, force bool) {
+	if !force && time.Since(mod.logged) < plainLogInterval {
+		return
+	}
+	mod.logged = time.Now()
+
+	line := fmt.Sprintf("%s: %s", mod.name, mod.stage)
+	if mod.stage == cmd.EventDownloading {
+		line += " " + formatBytes(mod.current)
+		if mod.total > 0 {
+			line += fmt.Sprintf("/%s (%d%%)", formatBytes(mod.total), mod.current*100/mod.total)
+		}
+	}
+	fmt.Fprintln(p.out, line)
+
+	if mod.done {
+		p.forget()
+	}
+}
+
+//draw redraws all bars that are still in progress. Finished bars stay on screen
+func (p *progressPrinter) draw(force bool) {
+	if !p.tty {
+		p.forget()
+		return
+	}
+
+	if !force && time.Since(p.last) < redrawInterval {
+		return
+	}
+	p.last = time.Now()
+
+	if p.drawn > 0 {
+		fmt.Fprintf(p.out, "\x1b[%dA", p.drawn)
+	}
+	for _, mod := range p.mods {
+		fmt.Fprintf(p.out, "\x1b[2K%s\n", mod.bar())
+	}
+
+	p.drawn = len(p.mods)
+	p.forget()
+}
+
+//forget drops finished mods from the top of the list so they are never redrawn
+func (p *progressPrinter) forget() {
+	for len(p.mods) > 0 && p.mods[0].done {
+		p.mods = p.mods[1:]
+		if p.drawn > 0 {
+			p.drawn--
+		}
+	}
+}
+
+func (mod *modProgress) bar() string {
+	if mod.done {
+		return fmt.Sprintf("%-24s done", mod.name)
+	}
+
+	if mod.stage != cmd.EventDownloading && mod.stage != cmd.EventExtracting {
+		return fmt.Sprintf("%-24s %s", mod.name, mod.stage)
+	}
+
+	filled := 0
+	if mod.total > 0 {
+		filled = int(mod.current * barWidth / mod.total)
+	}
+	if filled > barWidth {
+		filled = barWidth
+	}
+	bar := "[" + strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled) + "]"
+
+	if mod.stage == cmd.EventExtracting {
+		return fmt.Sprintf("%-24s %s %d/%d files  extracting", mod.name, bar, mod.current, mod.total)
+	}
+
+	line := fmt.Sprintf("%-24s %s %s", mod.name, bar, formatBytes(mod.current))
+	if mod.total > 0 {
+		line += "/" + formatBytes(mod.total)
+	}
+
+	elapsed := time.Since(mod.started).Seconds()
+	if elapsed > 0 && mod.current > 0 {
+		speed := float64(mod.current) / elapsed
+		line += fmt.Sprintf("  %s/s", formatBytes(int64(speed)))
+		if mod.total > 0 {
+			eta := time.Duration(float64(mod.total-mod.current)/speed) * time.Second
+			line += fmt.Sprintf("  ETA %s", eta)
+		}
+	}
+	return line
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+//isTerminal checks if the file is a terminal. Character devices like /dev/null are not
+func isTerminal(file *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
@@ -0,0 +1,14 @@
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!windows
+
+package main
+
+import "os"
+
+//isTerminal checks if the file is a character device since terminals can not be detected reliably
+func isTerminal(file *os.File) bool {
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
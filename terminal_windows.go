@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+//isTerminal checks if the file is a console
+func isTerminal(file *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(file.Fd()), &mode) == nil
+}
@@ -1,7 +1,11 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/CCDirectLink/CCUpdaterCLI/cmd"
+)
 
 func printVersion() {
-	fmt.Println("CrossCode Mod Updater v1.2.0-dev")
+	fmt.Printf("CrossCode Mod Updater v%s\n", cmd.Version)
 }